@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// githubEscaper escapes the data of a GitHub Actions workflow command
+var githubEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// githubPropertyEscaper escapes the properties of a GitHub Actions workflow command
+var githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// annotationFile returns the path of the go.mod file of root relative to the top of its git working
+// tree, as CI annotations expect, or go.mod when root is not in a git working tree or git isn't
+// installed
+func annotationFile(root string) (string, error) {
+	top, found, err := gitToplevel(root)
+	if errors.Is(err, exec.ErrNotFound) {
+		return "go.mod", nil
+	}
+	if err != nil || !found {
+		return "go.mod", err
+	}
+
+	// git resolves symbolic links
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(top, filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// WriteAnnotations writes a CI annotation for each denied result, pointing at the line of the go.mod
+// file requiring its module. Format is either github, for GitHub Actions workflow commands, or line,
+// for the file:line: message format understood by most problem matchers.
+func WriteAnnotations(w io.Writer, results []wwhrd.PackageResult, reqs map[string]*wwhrd.Requirement, file, format string) error {
+	for _, r := range results {
+		if r.Decision != wwhrd.DecisionDenied {
+			continue
+		}
+
+		msg := fmt.Sprintf("Non-Approved license %s found in package %s", r.License, r.Package)
+		if r.Module != "" {
+			msg += fmt.Sprintf(" (module %s %s)", r.Module, r.Version)
+		}
+
+		var line int
+		if req, ok := reqs[r.Module]; ok {
+			line = req.Line
+		}
+
+		var err error
+		switch format {
+		case "github":
+			props := "file=" + githubPropertyEscaper.Replace(file)
+			if line > 0 {
+				props += fmt.Sprintf(",line=%d", line)
+			}
+			_, err = fmt.Fprintf(w, "::error %s,title=%s::%s\n", props, githubPropertyEscaper.Replace("wwhrd"), githubEscaper.Replace(msg))
+		case "line":
+			if line > 0 {
+				_, err = fmt.Fprintf(w, "%s:%d: %s\n", file, line, msg)
+			} else {
+				_, err = fmt.Fprintf(w, "%s: %s\n", file, msg)
+			}
+		default:
+			return fmt.Errorf("unknown annotation format %q", format)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAnnotations(t *testing.T) {
+	results := []wwhrd.PackageResult{
+		{Package: "github.com/a/b", Module: "github.com/a/b", Version: "v1.0.0", License: "MIT", Decision: wwhrd.DecisionApproved},
+		{Package: "github.com/c/d/e", Module: "github.com/c/d", Version: "v0.2.0", License: "GPL-2.0", Decision: wwhrd.DecisionDenied},
+		{Package: "github.com/f/g", License: "UNKNOWN", Decision: wwhrd.DecisionDenied},
+	}
+	reqs := map[string]*wwhrd.Requirement{
+		"github.com/c/d": {Path: "github.com/c/d", Version: "v0.2.0", Line: 12},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteAnnotations(&out, results, reqs, "go.mod", "github"))
+	assert.Equal(t, "::error file=go.mod,line=12,title=wwhrd::Non-Approved license GPL-2.0 found in package github.com/c/d/e (module github.com/c/d v0.2.0)\n"+
+		"::error file=go.mod,title=wwhrd::Non-Approved license UNKNOWN found in package github.com/f/g\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, WriteAnnotations(&out, results, reqs, "tools/go.mod", "line"))
+	assert.Equal(t, "tools/go.mod:12: Non-Approved license GPL-2.0 found in package github.com/c/d/e (module github.com/c/d v0.2.0)\n"+
+		"tools/go.mod: Non-Approved license UNKNOWN found in package github.com/f/g\n", out.String())
+
+	assert.Error(t, WriteAnnotations(&out, results, reqs, "go.mod", "xml"))
+}
+
+func TestAnnotationFile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir, err := ioutil.TempDir("", "TestAnnotationFile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file, err := annotationFile(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "go.mod", file)
+
+	// the module lives in a sub directory of the repository
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "tools", "lint"), 0755))
+
+	file, err = annotationFile(filepath.Join(dir, "tools", "lint"))
+	assert.NoError(t, err)
+	assert.Equal(t, "tools/lint/go.mod", file)
+
+	// without git, as in the Docker image
+	t.Setenv("PATH", dir)
+	file, err = annotationFile(filepath.Join(dir, "tools", "lint"))
+	assert.NoError(t, err)
+	assert.Equal(t, "go.mod", file)
+}
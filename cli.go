@@ -2,11 +2,15 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"strings"
+	"os/signal"
+	"time"
 
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
 	"github.com/jessevdk/go-flags"
 	log "github.com/sirupsen/logrus"
 )
@@ -15,14 +19,33 @@ type cliOpts struct {
 	List        `command:"list" alias:"ls" description:"List licenses"`
 	Check       `command:"check" alias:"chk" description:"Check licenses against config file"`
 	Graph       `command:"graph" alias:"dot" description:"Generate dot graph dependency tree"`
+	Notice      `command:"notice" description:"Generate third-party notices file"`
+	Report      `command:"report" description:"Generate a compliance report"`
+	Why         `command:"why" description:"Explain how a package is imported"`
+	Snapshot    `command:"snapshot" description:"Record the license inventory"`
+	Diff        `command:"diff" description:"Compare two license inventory snapshots"`
+	Cache       `command:"cache" description:"Manage the license scan cache"`
 	VersionFlag func() error `long:"version" short:"v" description:"Show CLI version"`
 
-	Quiet func() error `short:"q" long:"quiet" description:"quiet mode, do not log accepted packages"`
-	Debug func() error `short:"d" long:"debug" description:"verbose mode, log everything"`
+	Quiet func() error    `short:"q" long:"quiet" description:"quiet mode, do not log accepted packages"`
+	Debug func() error    `short:"d" long:"debug" description:"verbose mode, log everything"`
+	Jobs  func(int) error `short:"j" long:"jobs" description:"number of concurrent workers walking imports and scanning licenses, defaults to the number of CPUs"`
+
+	Timeout func(time.Duration) error `long:"timeout" description:"abort walking imports after this duration, e.g. 30s"`
+	Strict  func() error              `long:"strict" description:"stop at the first path that can't be walked or scanned, rather than carrying on and failing once done"`
+
+	CacheDir func(string) error `long:"cache-dir" description:"directory of the license scan cache, defaults to wwhrd in the user cache directory"`
+	NoCache  func() error       `long:"no-cache" description:"do not use the license scan cache"`
 }
 
 type List struct {
 	NoColor           bool    `long:"no-color" description:"disable colored output"`
+	Format            string  `long:"format" description:"output format" choice:"text" choice:"csv" choice:"tsv" default:"text"`
+	Columns           string  `long:"columns" description:"comma separated list of columns for csv and tsv formats (package, module, version, license, file, source, coverage, detector, confidence, decision, reason, direct)" default:"package,module,version,license,file,coverage,decision,direct"`
+	Template          string  `long:"template" description:"Go text/template file used to render the results, overrides --format"`
+	ReverseDeps       string  `long:"rdeps" description:"list the packages depending on this package instead of licenses"`
+	File              string  `short:"f" long:"file" description:"config file used to fill the decision column and to apply overrides, use - for stdin"`
+	Output            string  `short:"o" long:"output" description:"output file for csv, tsv and template output, use - for stdout" default:"-"`
 	CoverageThreshold float64 `short:"c" long:"coverage" description:"coverage threshold is the minimum percentage of the file that must contain license text" default:"75"`
 	CheckTestFiles    bool    `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
 }
@@ -30,15 +53,83 @@ type List struct {
 type Check struct {
 	File              string  `short:"f" long:"file" description:"input file, use - for stdin" default:".wwhrd.yml"`
 	NoColor           bool    `long:"no-color" description:"disable colored output"`
+	Format            string  `long:"format" description:"additionally print failures as CI annotations on stdout" choice:"text" choice:"github" choice:"line" default:"text"`
+	Template          string  `long:"template" description:"Go text/template file used to render the results on stdout, overrides --format"`
+	Baseline          string  `long:"baseline" description:"snapshot file to compare the license inventory with, only failing on violations that are not in it"`
+	Since             string  `long:"since" description:"only check the modules added or changed since this git ref"`
+	UpdateBaseline    bool    `long:"update-baseline" description:"write the current license inventory to the baseline file, .wwhrd-baseline.json unless --baseline is set"`
 	CoverageThreshold float64 `short:"c" long:"coverage" description:"coverage threshold is the minimum percentage of the file that must contain license text" default:"75"`
 	CheckTestFiles    bool    `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
 }
 
 type Graph struct {
-	File           string `short:"o" long:"output" description:"output file, use - for stdout" default:"wwhrd-graph.dot"`
-	CheckTestFiles bool   `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
+	File              string   `short:"o" long:"output" description:"output file, use - for stdout, defaults to wwhrd-graph with the extension of the format"`
+	Config            string   `short:"f" long:"file" description:"config file used to color nodes by license decision, use - for stdin"`
+	CoverageThreshold float64  `short:"c" long:"coverage" description:"coverage threshold is the minimum percentage of the file that must contain license text" default:"75"`
+	CheckTestFiles    bool     `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
+	Tree              bool     `long:"tree" description:"only keep the edges of a breadth-first spanning tree from the root"`
+	Format            string   `long:"format" description:"graph format" choice:"dot" choice:"mermaid" choice:"graphml" choice:"json" choice:"d2" default:"dot"`
+	Depth             int      `long:"depth" description:"maximum distance from the root of the packages to show, 0 means no limit" default:"0"`
+	Focus             []string `long:"focus" description:"only show the packages leading to this package, can be repeated"`
+	Cluster           bool     `long:"cluster" description:"group packages by module (dot and mermaid formats)"`
+	HideStd           bool     `long:"hide-std" description:"hide golang.org/x packages"`
+	HideAllowed       bool     `long:"hide-allowed" description:"hide packages with an approved license, requires a config file"`
+	Violations        bool     `long:"violations" description:"only show the packages leading to a Non-Approved license, requires a config file"`
+	Reverse           string   `long:"reverse" description:"only show the packages depending on this package, with edges reversed"`
+}
+
+type Notice struct {
+	File              string  `short:"f" long:"file" description:"config file, for the license files patterns, the custom licenses and the overrides, use - for stdin"`
+	Output            string  `short:"o" long:"output" description:"output file, use - for stdout" default:"THIRD_PARTY_NOTICES"`
+	Template          string  `long:"template" description:"Go text/template file used to render the notices"`
+	CoverageThreshold float64 `short:"c" long:"coverage" description:"coverage threshold is the minimum percentage of the file that must contain license text" default:"75"`
+	CheckTestFiles    bool    `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
+}
+
+type Report struct {
+	File              string  `short:"f" long:"file" description:"input file, use - for stdin" default:".wwhrd.yml"`
+	Output            string  `short:"o" long:"output" description:"output file, use - for stdout" default:"-"`
+	Format            string  `long:"format" description:"report format" choice:"html" choice:"markdown" default:"markdown"`
+	CoverageThreshold float64 `short:"c" long:"coverage" description:"coverage threshold is the minimum percentage of the file that must contain license text" default:"75"`
+	CheckTestFiles    bool    `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
+}
+
+type Why struct {
+	Entries           []string `short:"e" long:"entry" description:"also explain the chains starting from this package, can be repeated"`
+	MaxChains         int      `long:"max-chains" description:"maximum number of chains shown for each package, 0 means no limit" default:"10"`
+	Output            string   `short:"o" long:"output" description:"output file, use - for stdout" default:"-"`
+	File              string   `short:"f" long:"file" description:"config file, explain every package with a Non-Approved license when no package is given"`
+	CoverageThreshold float64  `short:"c" long:"coverage" description:"coverage threshold is the minimum percentage of the file that must contain license text" default:"75"`
+	CheckTestFiles    bool     `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
+	Args              struct {
+		Packages []string `positional-arg-name:"package"`
+	} `positional-args:"yes"`
+}
+
+type Snapshot struct {
+	Output            string  `short:"o" long:"output" description:"output file, use - for stdout" default:"-"`
+	File              string  `short:"f" long:"file" description:"config file used to record the decision of each package, use - for stdin"`
+	CoverageThreshold float64 `short:"c" long:"coverage" description:"coverage threshold is the minimum percentage of the file that must contain license text" default:"75"`
+	CheckTestFiles    bool    `short:"t" long:"check-test-files" description:"check imported dependencies for test files"`
 }
 
+type Diff struct {
+	NoColor bool `long:"no-color" description:"disable colored output"`
+	Args    struct {
+		Old string `positional-arg-name:"old" required:"yes"`
+		New string `positional-arg-name:"new" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type Cache struct {
+	Clean CacheClean `command:"clean" description:"Remove the cached license scans"`
+}
+
+type CacheClean struct{}
+
+// defaultBaselineFile is where --update-baseline writes the baseline when --baseline is not set
+const defaultBaselineFile = ".wwhrd-baseline.json"
+
 const VersionHelp flags.ErrorType = 1961
 
 var (
@@ -57,6 +148,31 @@ func setDebug() error {
 	return nil
 }
 
+// walkTimeout is the maximum duration of a command walking imports, set with --timeout
+var walkTimeout time.Duration
+
+func setTimeout(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("--timeout must be positive, got %s", d)
+	}
+	walkTimeout = d
+	return nil
+}
+
+// commandContext returns the context of a command, cancelled on interrupt or once --timeout has elapsed
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if walkTimeout == 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, walkTimeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
 func newCli() *flags.Parser {
 	opts := cliOpts{
 		VersionFlag: func() error {
@@ -67,6 +183,13 @@ func newCli() *flags.Parser {
 		},
 		Quiet: setQuiet,
 		Debug: setDebug,
+		Jobs:  setJobs,
+
+		Timeout: setTimeout,
+		Strict:  setStrict,
+
+		CacheDir: setCacheDir,
+		NoCache:  setNoCache,
 	}
 	parser := flags.NewParser(&opts, flags.HelpFlag|flags.PassDoubleDash)
 	parser.LongDescription = "What would Henry Rollins do?"
@@ -75,48 +198,297 @@ func newCli() *flags.Parser {
 }
 
 func (g *Graph) Execute(args []string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	var inc incomplete
+
 	root, err := rootDir()
 	if err != nil {
 		return err
 	}
 
-	log.Infof("Generating DOT graph")
+	if (g.HideAllowed || g.Violations) && g.Config == "" {
+		return fmt.Errorf("--hide-allowed and --violations require a config file")
+	}
+
+	log.Infof("Generating %s graph", g.Format)
+
+	opts := scanOptions(root, nil, g.CoverageThreshold, g.CheckTestFiles)
+
+	var graph *wwhrd.Graph
+	var results map[string]wwhrd.PackageResult
+	if g.Config != "" {
+		if opts.Config, err = wwhrd.ReadConfigFile(g.Config); err != nil {
+			return err
+		}
 
-	dotGraph, err := GraphImports(root, g.CheckTestFiles)
+		res, err := wwhrd.Scan(ctx, opts)
+		if err = inc.check(err); err != nil {
+			return err
+		}
+		graph, results = res.Graph, resultsByPackage(res.Packages)
+	} else {
+		graph, err = wwhrd.Walk(ctx, opts)
+		if err = inc.check(err); err != nil {
+			return err
+		}
+	}
+
+	v := newGraphView(graph, g.Tree)
+	v.results = results
+	if g.Reverse != "" {
+		if !graph.Has(g.Reverse) {
+			return fmt.Errorf("package %q not found in the dependency graph", g.Reverse)
+		}
+		v.reverse(g.Reverse)
+	}
+	v.filter(graphFilter{
+		depth:       g.Depth,
+		focus:       g.Focus,
+		violations:  g.Violations,
+		hideStd:     g.HideStd,
+		hideAllowed: g.HideAllowed,
+	})
+
+	if g.Cluster {
+		mods, err := wwhrd.ReadVendorModules(root)
+		if err != nil {
+			return err
+		}
+		v.modules = make(map[string]string)
+		for _, pkg := range v.nodes {
+			if m := mods.Lookup(pkg); m != nil {
+				v.modules[pkg] = m.Path
+			}
+		}
+	}
+	out, err := v.render(g.Format)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	if g.File == "-" {
-		mf := bufio.NewWriter(os.Stdout)
-		defer mf.Flush()
+	// the default output file follows the format
+	file := g.File
+	if file == "" {
+		file = "wwhrd-graph." + graphFormats[g.Format]
+	}
+
+	if err := writeOutput(file, "Graph", func(w io.Writer) error {
+		_, err := w.Write([]byte(out))
+		return err
+	}); err != nil {
+		return err
+	}
 
-		_, err = mf.Write([]byte(dotGraph))
+	return inc.err()
+}
+
+func (n *Notice) Execute(args []string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	var inc incomplete
+
+	var tmpl string
+	if n.Template != "" {
+		b, err := ioutil.ReadFile(n.Template)
 		if err != nil {
+			return fmt.Errorf("can't read template file: %s", err)
+		}
+		tmpl = string(b)
+	}
+
+	root, err := rootDir()
+	if err != nil {
+		return err
+	}
+
+	t, err := readOptionalConfigFile(n.File)
+	if err != nil {
+		return err
+	}
+	isLicense, err := wwhrd.LicenseFileMatcher(t)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Generating third-party notices")
+
+	res, err := wwhrd.Scan(ctx, scanOptions(root, t, n.CoverageThreshold, n.CheckTestFiles))
+	if err = inc.check(err); err != nil {
+		return err
+	}
+	notices, err := GetNotices(root, res.Packages, isLicense)
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutput(n.Output, "Notices", func(w io.Writer) error {
+		return RenderNotices(w, notices, tmpl)
+	}); err != nil {
+		return err
+	}
+
+	return inc.err()
+}
+
+func (r *Report) Execute(args []string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	var inc incomplete
+
+	t, err := wwhrd.ReadConfigFile(r.File)
+	if err != nil {
+		return err
+	}
+
+	root, err := rootDir()
+	if err != nil {
+		return err
+	}
+
+	res, err := wwhrd.Scan(ctx, scanOptions(root, t, r.CoverageThreshold, r.CheckTestFiles))
+	if err = inc.check(err); err != nil {
+		return err
+	}
+
+	if err := writeOutput(r.Output, "Report", func(w io.Writer) error {
+		reporter, err := newReporter(w, reporterOptions{format: r.Format, root: root})
+		if err != nil {
+			return err
+		}
+		return reporter.Report(res.Packages)
+	}); err != nil {
+		return err
+	}
+
+	return inc.err()
+}
+
+func (y *Why) Execute(args []string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	var inc incomplete
+
+	if len(y.Args.Packages) == 0 && y.File == "" {
+		return fmt.Errorf("a package or a config file is required")
+	}
+
+	root, err := rootDir()
+	if err != nil {
+		return err
+	}
+
+	opts := scanOptions(root, nil, y.CoverageThreshold, y.CheckTestFiles)
+
+	var graph *wwhrd.Graph
+	targets := y.Args.Packages
+	if len(targets) == 0 {
+		if opts.Config, err = wwhrd.ReadConfigFile(y.File); err != nil {
+			return err
+		}
+
+		res, err := wwhrd.Scan(ctx, opts)
+		if err = inc.check(err); err != nil {
+			return err
+		}
+		graph = res.Graph
+		for _, r := range res.Denied() {
+			targets = append(targets, r.Package)
+		}
+	} else {
+		graph, err = wwhrd.Walk(ctx, opts)
+		if err = inc.check(err); err != nil {
 			return err
 		}
+	}
 
+	if err := writeOutput(y.Output, "Import chains", func(w io.Writer) error {
+		for _, target := range targets {
+			for _, from := range append([]string{wwhrd.RootPackage}, y.Entries...) {
+				chains, truncated := importChains(graph.Imports(), from, target, y.MaxChains)
+				if err := writeChains(w, chains, truncated, from, target); err != nil {
+					return err
+				}
+			}
+		}
 		return nil
+	}); err != nil {
+		return err
 	}
 
-	log.Debug("Creating file... ", g.File)
-	mf, err := os.Create(g.File)
+	return inc.err()
+}
+
+func (s *Snapshot) Execute(args []string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	var inc incomplete
+
+	var t *wwhrd.Config
+	if s.File != "" {
+		var err error
+		if t, err = wwhrd.ReadConfigFile(s.File); err != nil {
+			return err
+		}
+	}
+
+	root, err := rootDir()
 	if err != nil {
 		return err
 	}
-	defer mf.Close()
 
-	_, err = mf.Write([]byte(dotGraph))
+	res, err := wwhrd.Scan(ctx, scanOptions(root, t, s.CoverageThreshold, s.CheckTestFiles))
+	if err = inc.check(err); err != nil {
+		return err
+	}
+
+	if err := writeOutput(s.Output, "Snapshot", NewSnapshot(res.Packages).Write); err != nil {
+		return err
+	}
+
+	return inc.err()
+}
+
+func (d *Diff) Execute(args []string) error {
+
+	if d.NoColor {
+		log.SetFormatter(&log.TextFormatter{DisableColors: true})
+	} else {
+		log.SetFormatter(&log.TextFormatter{ForceColors: true})
+	}
+
+	old, err := ReadSnapshot(d.Args.Old)
+	if err != nil {
+		return err
+	}
+	latest, err := ReadSnapshot(d.Args.New)
+	if err != nil {
+		return err
+	}
+
+	logDiff(DiffSnapshots(old, latest))
+
+	return nil
+}
+
+func (c *CacheClean) Execute(args []string) error {
+	dir, err := cacheDirectory()
 	if err != nil {
 		return err
 	}
 
-	log.Infof("Graph saved in %q", g.File)
+	if err := wwhrd.CleanCache(dir); err != nil {
+		return err
+	}
+	log.Infof("Cache cleaned in %q", dir)
 
 	return nil
 }
 
 func (l *List) Execute(args []string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	var inc incomplete
 
 	if l.NoColor {
 		log.SetFormatter(&log.TextFormatter{DisableColors: true})
@@ -129,23 +501,74 @@ func (l *List) Execute(args []string) error {
 		return err
 	}
 
-	pkgs, err := WalkImports(root, l.CheckTestFiles)
+	if l.ReverseDeps != "" {
+		return l.listReverseDependencies(ctx, root)
+	}
+
+	t, err := readOptionalConfigFile(l.File)
 	if err != nil {
 		return err
 	}
-	lics := GetLicenses(root, pkgs, l.CoverageThreshold)
 
-	for k, v := range lics {
+	res, err := wwhrd.Scan(ctx, scanOptions(root, t, l.CoverageThreshold, l.CheckTestFiles))
+	if err = inc.check(err); err != nil {
+		return err
+	}
+	warnImportCycles(res.Graph)
+	if err := warnDuplicateModules(root); err != nil {
+		return err
+	}
+
+	output := l.Output
+	if l.Format == "text" && l.Template == "" {
+		// logs are not written to the output file
+		output = "-"
+	}
+
+	if err := writeOutput(output, "List", func(w io.Writer) error {
+		reporter, err := newReporter(w, reporterOptions{format: l.Format, columns: l.Columns, template: l.Template, root: root})
+		if err != nil {
+			return err
+		}
+		return reporter.Report(res.Packages)
+	}); err != nil {
+		return err
+	}
+
+	return inc.err()
+}
+
+func (l *List) listReverseDependencies(ctx context.Context, root string) error {
+	var inc incomplete
+	graph, err := wwhrd.Walk(ctx, scanOptions(root, nil, l.CoverageThreshold, l.CheckTestFiles))
+	if err = inc.check(err); err != nil {
+		return err
+	}
+	if !graph.Has(l.ReverseDeps) {
+		return fmt.Errorf("package %q not found in the dependency graph", l.ReverseDeps)
+	}
+
+	modulePath, err := wwhrd.ReadModulePath(root)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range reverseDependencies(graph, l.ReverseDeps, modulePath) {
 		log.WithFields(log.Fields{
-			"package": k,
-			"license": v,
-		}).Info("Found License")
+			"package":    d.Package,
+			"dependency": l.ReverseDeps,
+			"direct":     d.Direct,
+			"local":      d.Local,
+		}).Info("Found reverse dependency")
 	}
 
-	return nil
+	return inc.err()
 }
 
 func (c *Check) Execute(args []string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	var inc incomplete
 
 	if c.NoColor {
 		log.SetFormatter(&log.TextFormatter{DisableColors: true})
@@ -153,115 +576,134 @@ func (c *Check) Execute(args []string) error {
 		log.SetFormatter(&log.TextFormatter{ForceColors: true})
 	}
 
-	var config []byte
+	t, err := wwhrd.ReadConfigFile(c.File)
+	if err != nil {
+		return err
+	}
 
-	if c.File == "-" {
-		mf := bufio.NewReader(os.Stdin)
-		var err error
-		config, err = ioutil.ReadAll(mf)
-		if err != nil {
-			return err
-		}
-	} else {
-		if _, err := os.Stat(c.File); os.IsNotExist(err) {
-			return fmt.Errorf("can't read config file: %s", err)
-		}
+	log.Debugf("Loaded config: %+v", t)
 
-		f, err := os.Open(c.File)
-		if err != nil {
-			return err
-		}
+	root, err := rootDir()
+	if err != nil {
+		return err
+	}
 
-		config, err = ioutil.ReadAll(f)
-		if err != nil {
-			return err
-		}
+	if c.Since != "" && c.UpdateBaseline {
+		return fmt.Errorf("--update-baseline can't be used with --since")
+	}
 
-		if err = f.Close(); err != nil {
+	opts := scanOptions(root, t, c.CoverageThreshold, c.CheckTestFiles)
+	if c.Since != "" {
+		if opts.Include, err = changedSince(root, c.Since); err != nil {
 			return err
 		}
+	}
 
+	res, err := wwhrd.Scan(ctx, opts)
+	if err = inc.check(err); err != nil {
+		return err
+	}
+	warnImportCycles(res.Graph)
+	if err := warnDuplicateModules(root); err != nil {
+		return err
 	}
+	results := res.Packages
 
-	t, err := ReadConfig(config)
-	if err != nil {
-		err = fmt.Errorf("can't read config file: %s", err)
+	// a baseline missing the packages that could not be scanned would hide their violations
+	if c.UpdateBaseline && inc.err() != nil {
+		return inc.err()
+	}
+	if err := c.applyBaseline(results, opts.Include); err != nil {
 		return err
 	}
 
-	log.Debugf("Loaded config: %+v", t)
+	mf := bufio.NewWriter(os.Stdout)
+	defer mf.Flush()
 
-	root, err := rootDir()
+	reporter, err := newReporter(mf, reporterOptions{format: c.Format, template: c.Template, root: root})
 	if err != nil {
 		return err
 	}
-
-	pkgs, err := WalkImports(root, c.CheckTestFiles)
-	if err != nil {
+	if err = reporter.Report(results); err != nil {
 		return err
 	}
-	lics := GetLicenses(root, pkgs, c.CoverageThreshold)
 
-	// Make a map out of the blacklist
-	blacklist := make(map[string]bool)
-	for _, v := range t.Denylist {
-		blacklist[v] = true
+	if err := res.Check(); err != nil {
+		return err
 	}
 
-	// Make a map out of the whitelist
-	whitelist := make(map[string]bool)
-	for _, v := range t.Allowlist {
-		whitelist[v] = true
-	}
+	return inc.err()
+}
 
-	// Make a map out of the exceptions list
-	exceptions := make(map[string]bool)
-	exceptionsWildcard := make(map[string]bool)
-	for _, v := range t.Exceptions {
-		if strings.HasSuffix(v, "/...") {
-			exceptionsWildcard[strings.TrimSuffix(v, "/...")] = true
-		} else {
-			exceptions[v] = true
+// applyBaseline tolerates the violations recorded in the baseline, after regenerating it
+// when --update-baseline is set. Only the packages of the baseline selected by include, when set,
+// are compared with the results.
+func (c *Check) applyBaseline(results []wwhrd.PackageResult, include func(pkg string) bool) error {
+	var baseline *LicenseSnapshot
+	switch {
+	case c.UpdateBaseline:
+		file := c.Baseline
+		if file == "" {
+			file = defaultBaselineFile
+		}
+		baseline = NewBaseline(results)
+		if err := writeOutput(file, "Baseline", baseline.Write); err != nil {
+			return err
+		}
+	case c.Baseline != "":
+		var err error
+		if baseline, err = ReadSnapshot(c.Baseline); err != nil {
+			return err
+		}
+		// baselines only record violations, full snapshots the whole inventory
+		current := NewSnapshot(results)
+		if baseline.Baseline {
+			current = NewBaseline(results)
 		}
+		logDiff(DiffSnapshots(baseline.Filter(include), current))
+	default:
+		return nil
 	}
 
-PackageList:
-	for pkg, lic := range lics {
-		contextLogger := log.WithFields(log.Fields{
-			"package": pkg,
-			"license": lic,
-		})
+	if n := ApplyBaseline(results, baseline); n > 0 {
+		log.Debugf("%d violations found in the baseline", n)
+	}
 
-		// License is whitelisted and not specified in blacklist
-		if whitelist[lic] && !blacklist[lic] {
-			contextLogger.Info("Found Approved license")
-			continue PackageList
-		}
+	return nil
+}
 
-		// if we have exceptions wildcards, let's run through them
-		if len(exceptionsWildcard) > 0 {
-			for wc := range exceptionsWildcard {
-				if strings.HasPrefix(pkg, wc) {
-					// we have a match
-					contextLogger.Warn("Found exceptioned package")
-					continue PackageList
-				}
-			}
-		}
+// writeOutput calls render with stdout when file is -, or with a newly created file otherwise
+func writeOutput(file string, what string, render func(w io.Writer) error) error {
+	if file == "-" {
+		mf := bufio.NewWriter(os.Stdout)
+		defer mf.Flush()
 
-		// match single-package exceptions
-		if _, exists := exceptions[pkg]; exists {
-			contextLogger.Warn("Found exceptioned package")
-			continue PackageList
-		}
+		return render(mf)
+	}
 
-		// no matches, it's a non-approved license
-		contextLogger.Error("Found Non-Approved license")
-		err = fmt.Errorf("Non-Approved license found")
+	log.Debug("Creating file... ", file)
+	mf, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer mf.Close()
 
+	if err = render(mf); err != nil {
+		return err
 	}
 
-	return err
+	log.Infof("%s saved in %q", what, file)
+
+	return nil
+}
+
+// readOptionalConfigFile reads the config file of the commands that can do without one, only when
+// given
+func readOptionalConfigFile(file string) (*wwhrd.Config, error) {
+	if file == "" {
+		return nil, nil
+	}
+	return wwhrd.ReadConfigFile(file)
 }
 
 func rootDir() (string, error) {
@@ -2,10 +2,16 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,11 +33,11 @@ func TestCliListSuccess(t *testing.T) {
 	}{
 		{
 			[]string{"list"},
-			[]string{`level=info msg="Found License" license=BSD-3-Clause package=github.com/fake/package`, `level=info msg="Found License" license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
+			[]string{`level=info msg="Found License" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`, `level=info msg="Found License" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
 		},
 		{
 			[]string{"ls"},
-			[]string{`level=info msg="Found License" license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`, `level=info msg="Found License" license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
+			[]string{`level=info msg="Found License" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`, `level=info msg="Found License" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
 		},
 	}
 
@@ -46,6 +52,37 @@ func TestCliListSuccess(t *testing.T) {
 	}
 }
 
+func TestCliListOverrides(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliListOverrides")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	config := "allowlist:\n  - MIT\noverrides:\n  github.com/fake/package:\n    license: MIT\n    reason: relicensed\n"
+	assert.NoError(t, ioutil.WriteFile(".wwhrd.yml", []byte(config), 0666))
+
+	// the config file is only read when given
+	_, err = newCli().ParseArgs([]string{"list", "--no-color"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `level=info msg="Found License" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`)
+
+	_, err = newCli().ParseArgs([]string{"list", "-f", ".wwhrd.yml", "--no-color"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `level=info msg="Found Approved license" file=vendor/github.com/fake/package/LICENSE license=MIT package=github.com/fake/package`)
+
+	// overridden packages keep their license text in the notices
+	_, err = newCli().ParseArgs([]string{"notice", "-f", ".wwhrd.yml", "-o", "NOTICES"})
+	assert.NoError(t, err)
+	notices, err := ioutil.ReadFile("NOTICES")
+	assert.NoError(t, err)
+	assert.Contains(t, string(notices), "  * github.com/fake/nested/inside/a/package\n  * github.com/fake/package\n\nCopyright (c) 2016, Fabio Rapposelli")
+}
+
 func TestCliQuiet(t *testing.T) {
 	initial := log.GetLevel()
 	defer log.SetLevel(initial)
@@ -75,22 +112,22 @@ func TestCliCheck(t *testing.T) {
 	}{
 		{
 			[]string{"check"},
-			[]string{`level=info msg="Found Approved license" license=BSD-3-Clause package=github.com/fake/package`, `level=info msg="Found Approved license" license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
+			[]string{`level=info msg="Found Approved license" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`, `level=info msg="Found Approved license" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
 			[]error{nil},
 		},
 		{
 			[]string{"check", "-f", ".wwhrd-ex.yml"},
-			[]string{`level=warning msg="Found exceptioned package" license=BSD-3-Clause package=github.com/fake/package`, `level=warning msg="Found exceptioned package" license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
+			[]string{`level=warning msg="Found exceptioned package" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`, `level=warning msg="Found exceptioned package" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
 			[]error{nil},
 		},
 		{
 			[]string{"check", "-f", ".wwhrd-exwc.yml"},
-			[]string{`level=warning msg="Found exceptioned package" license=BSD-3-Clause package=github.com/fake/package`, `level=warning msg="Found exceptioned package" license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
+			[]string{`level=warning msg="Found exceptioned package" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`, `level=warning msg="Found exceptioned package" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
 			[]error{nil},
 		},
 		{
 			[]string{"check", "-f", ".wwhrd-bl.yml"},
-			[]string{`level=error msg="Found Non-Approved license" license=BSD-3-Clause package=github.com/fake/package`, `level=error msg="Found Non-Approved license" license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
+			[]string{`level=error msg="Found Non-Approved license" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`, `level=error msg="Found Non-Approved license" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`},
 			[]error{fmt.Errorf("Non-Approved license found")},
 		},
 		{
@@ -107,13 +144,21 @@ func TestCliCheck(t *testing.T) {
 			[]string{"check", "-f", ".wwhrd-botched.yml"},
 			[]string{""},
 			[]error{fmt.Errorf(`can't read config file: yaml: unmarshal errors:
-  line 2: cannot unmarshal !!str ` + "`whiteli...`" + ` into main.Config`)},
+  line 2: cannot unmarshal !!str ` + "`whiteli...`" + ` into wwhrd.Config`)},
 		},
 	}
 
 	for _, c := range cases {
 		_, err = newCli().ParseArgs(append(c.inArgs, "--no-color"))
-		assert.Contains(t, c.err, err)
+		if c.err[0] == nil {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			var want []string
+			for _, e := range c.err {
+				want = append(want, e.Error())
+			}
+			assert.Contains(t, want, err.Error())
+		}
 
 		for _, want := range c.outputWantNoColor {
 			assert.Contains(t, out.String(), want)
@@ -121,3 +166,362 @@ func TestCliCheck(t *testing.T) {
 		out.Reset()
 	}
 }
+
+func TestCliCheckFormat(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliCheckFormat")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	goMod := "module github.com/fake/main\n\nrequire (\n\tgithub.com/fake/nested v0.1.0\n\tgithub.com/fake/package v1.2.3\n)\n"
+	assert.NoError(t, ioutil.WriteFile("go.mod", []byte(goMod), 0666))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join("vendor", "modules.txt"), []byte(mockModulesTxt), 0666))
+
+	// annotations are printed on stdout
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+	os.Stdout, err = os.Create("annotations.txt")
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"check", "-f", ".wwhrd-bl.yml", "--format", "github", "--no-color"})
+	assert.EqualError(t, err, "Non-Approved license found")
+	assert.NoError(t, os.Stdout.Close())
+
+	annotations, err := ioutil.ReadFile("annotations.txt")
+	assert.NoError(t, err)
+	assert.Contains(t, string(annotations), "::error file=go.mod,line=5,title=wwhrd::Non-Approved license BSD-3-Clause found in package github.com/fake/package (module github.com/fake/package v1.2.3)\n")
+	assert.Contains(t, string(annotations), "::error file=go.mod,line=4,title=wwhrd::Non-Approved license BSD-3-Clause found in package github.com/fake/nested/inside/a/package (module github.com/fake/nested v0.1.1)\n")
+	// the usual log output is kept
+	assert.Contains(t, out.String(), `level=error msg="Found Non-Approved license"`)
+}
+
+func TestCliCheckSinceBaseline(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliCheckSinceBaseline")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join("vendor", "modules.txt"), []byte(mockModulesTxt), 0666))
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join("vendor", "modules.txt"), []byte(strings.Replace(mockModulesTxt, "v1.2.3", "v1.3.0", 1)), 0666))
+
+	_, err = newCli().ParseArgs([]string{"snapshot", "-f", ".wwhrd.yml", "-o", "snapshot.json"})
+	assert.NoError(t, err)
+	out.Reset()
+
+	// the packages left out by --since are not reported as removed
+	_, err = newCli().ParseArgs([]string{"check", "--since", "HEAD", "--baseline", "snapshot.json", "--no-color"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "package=github.com/fake/package")
+	assert.NotContains(t, out.String(), "Found removed package")
+}
+
+func TestCliNotice(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliNotice")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"notice", "-o", "NOTICES"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `msg="Notices saved in \"NOTICES\""`)
+
+	notices, err := ioutil.ReadFile("NOTICES")
+	assert.NoError(t, err)
+	assert.Contains(t, string(notices), "Copyright 2016 The Fake Authors")
+	assert.Contains(t, string(notices), "  * github.com/fake/package")
+
+	// license scans are cached in the test cache directory
+	assert.DirExists(t, filepath.Join(cacheDir, "licenses"))
+
+	// license files are searched following the config file
+	assert.NoError(t, os.Rename("vendor/github.com/fake/package/LICENSE", "vendor/github.com/fake/package/LICENSE.BSD"))
+	assert.NoError(t, ioutil.WriteFile("license-files.yml", []byte("license_files:\n  - LICENSE.*\n"), 0666))
+	_, err = newCli().ParseArgs([]string{"notice", "-o", "NOTICES"})
+	assert.NoError(t, err)
+	notices, err = ioutil.ReadFile("NOTICES")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(notices), "  * github.com/fake/package\n")
+
+	_, err = newCli().ParseArgs([]string{"notice", "-f", "license-files.yml", "-o", "NOTICES"})
+	assert.NoError(t, err)
+	notices, err = ioutil.ReadFile("NOTICES")
+	assert.NoError(t, err)
+	assert.Contains(t, string(notices), "  * github.com/fake/package\n")
+
+	_, err = newCli().ParseArgs([]string{"notice", "--template", "NONEXISTENT"})
+	assert.Error(t, err)
+
+	_, err = newCli().ParseArgs([]string{"notice", "-f", "NONEXISTENT"})
+	assert.True(t, errors.Is(err, wwhrd.ErrConfigInvalid))
+}
+
+func TestCliGraph(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliGraph")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	// the default output file follows the format
+	_, err = newCli().ParseArgs([]string{"graph", "--format", "json"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `msg="Graph saved in \"wwhrd-graph.json\""`)
+	assert.FileExists(t, "wwhrd-graph.json")
+
+	// an explicit output file is kept as is
+	_, err = newCli().ParseArgs([]string{"graph", "--format", "json", "-o", "wwhrd-graph.dot"})
+	assert.NoError(t, err)
+	graph, err := ioutil.ReadFile("wwhrd-graph.dot")
+	assert.NoError(t, err)
+	assert.Contains(t, string(graph), `"id": "github.com/fake/package"`)
+}
+
+func TestCliWhy(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestCliWhy")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"why", "github.com/fake/package", "-o", "why.txt"})
+	assert.NoError(t, err)
+
+	why, err := ioutil.ReadFile("why.txt")
+	assert.NoError(t, err)
+	assert.Contains(t, string(why), "# github.com/fake/package (from ")
+	assert.Contains(t, string(why), "\tmockpkg.go:")
+
+	// every package with a Non-Approved license is explained
+	_, err = newCli().ParseArgs([]string{"why", "-f", ".wwhrd-bl.yml", "--max-chains", "1", "-o", "why.txt"})
+	assert.NoError(t, err)
+
+	why, err = ioutil.ReadFile("why.txt")
+	assert.NoError(t, err)
+	assert.Contains(t, string(why), "# github.com/fake/nested/inside/a/package (from ")
+	assert.Contains(t, string(why), "# github.com/fake/package (from ")
+
+	_, err = newCli().ParseArgs([]string{"why"})
+	assert.EqualError(t, err, "a package or a config file is required")
+}
+
+func TestCliReport(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliReport")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"report", "-f", ".wwhrd-ex.yml", "-o", "report.md"})
+	assert.NoError(t, err)
+
+	report, err := ioutil.ReadFile("report.md")
+	assert.NoError(t, err)
+	assert.Contains(t, string(report), "| github.com/fake/package | BSD-3-Clause | github.com/fake/package | - |")
+
+	_, err = newCli().ParseArgs([]string{"report", "--format", "html", "-o", "report.html"})
+	assert.NoError(t, err)
+
+	report, err = ioutil.ReadFile("report.html")
+	assert.NoError(t, err)
+	assert.Contains(t, string(report), `<a href="vendor/github.com/fake/nested/LICENSE">`)
+}
+
+func TestCliListExport(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestCliListExport")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"list", "--format", "csv", "--columns", "package,license,decision", "-f", ".wwhrd-bl.yml", "-o", "list.csv"})
+	assert.NoError(t, err)
+
+	list, err := ioutil.ReadFile("list.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "package,license,decision\ngithub.com/fake/nested/inside/a/package,BSD-3-Clause,denied\ngithub.com/fake/package,BSD-3-Clause,denied\n", string(list))
+
+	_, err = newCli().ParseArgs([]string{"list", "--format", "tsv", "--columns", "owner"})
+	assert.EqualError(t, err, `unknown column "owner"`)
+}
+
+func TestCliCheckTemplate(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestCliCheckTemplate")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	err = ioutil.WriteFile("list.tmpl", []byte(`{{range groupBy "license" .Results}}{{.Key}} {{len .Results}}{{end}}`), 0666)
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"list", "--template", "list.tmpl", "-o", "list.txt"})
+	assert.NoError(t, err)
+
+	list, err := ioutil.ReadFile("list.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "BSD-3-Clause 2", string(list))
+
+	_, err = newCli().ParseArgs([]string{"check", "-f", ".wwhrd-bl.yml", "--template", "list.tmpl"})
+	assert.EqualError(t, err, "Non-Approved license found")
+}
+
+func TestCliSnapshotDiff(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliSnapshotDiff")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"snapshot", "-o", "old.json"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `Snapshot saved in "old.json"`)
+
+	old, err := ioutil.ReadFile("old.json")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile("new.json", bytes.Replace(old, []byte("BSD-3-Clause"), []byte("MIT"), 1), 0644))
+
+	out.Reset()
+	_, err = newCli().ParseArgs([]string{"diff", "old.json", "new.json", "--no-color"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `level=warning msg="Found new license" license=MIT`)
+	assert.Contains(t, out.String(), `level=warning msg="Found relicensed package" license=MIT old_license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`)
+
+	out.Reset()
+	_, err = newCli().ParseArgs([]string{"check", "--baseline", "old.json", "--no-color"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `level=info msg="No license changes found"`)
+
+	_, err = newCli().ParseArgs([]string{"diff", "old.json", "NONEXISTENT"})
+	assert.Error(t, err)
+}
+
+func TestCliCheckBaseline(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliCheckBaseline")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	_, err = newCli().ParseArgs([]string{"check", "-f", ".wwhrd-bl.yml", "--update-baseline", "--no-color"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `level=info msg="Baseline saved in \".wwhrd-baseline.json\""`)
+	assert.Contains(t, out.String(), `level=warning msg="Found baselined package" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`)
+
+	baseline, err := ReadSnapshot(".wwhrd-baseline.json")
+	assert.NoError(t, err)
+	assert.True(t, baseline.Baseline)
+	assert.Equal(t, wwhrd.DecisionDenied, baseline.Packages[0].Decision)
+
+	out.Reset()
+	_, err = newCli().ParseArgs([]string{"check", "-f", ".wwhrd-bl.yml", "--baseline", ".wwhrd-baseline.json", "--no-color"})
+	assert.NoError(t, err)
+	assert.NotContains(t, out.String(), "Found Non-Approved license")
+	assert.Contains(t, out.String(), `level=info msg="No license changes found"`)
+
+	// a package missing from the baseline still fails the check
+	baseline.Packages = baseline.Packages[1:]
+	f, err := os.Create(".wwhrd-baseline.json")
+	assert.NoError(t, err)
+	assert.NoError(t, baseline.Write(f))
+	assert.NoError(t, f.Close())
+
+	out.Reset()
+	_, err = newCli().ParseArgs([]string{"check", "-f", ".wwhrd-bl.yml", "--baseline", ".wwhrd-baseline.json", "--no-color"})
+	assert.Equal(t, fmt.Errorf("Non-Approved license found"), err)
+	assert.Contains(t, out.String(), `level=error msg="Found Non-Approved license" file=vendor/github.com/fake/nested/LICENSE license=BSD-3-Clause package=github.com/fake/nested/inside/a/package`)
+}
+
+func TestCliCacheClean(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, err := ioutil.TempDir("", "TestCliCacheClean")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	initial := cacheDir
+	defer func() { cacheDir = initial }()
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "licenses", "00"), 0755))
+	_, err = newCli().ParseArgs([]string{"--cache-dir", dir, "cache", "clean"})
+	assert.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(dir, "licenses"))
+	assert.DirExists(t, dir)
+}
+
+func TestCliStrict(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+
+	dir, rm := mockGoPackageDir(t, "TestCliStrict")
+	defer rm()
+
+	// Change working dir to test dir
+	err := os.Chdir(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join("vendor/github.com/fake/package", "broken.go"), []byte("package fake\nimport (\n"), 0644))
+
+	// the other packages are still checked
+	_, err = newCli().ParseArgs([]string{"check", "--no-color"})
+	assert.IsType(t, &wwhrd.IncompleteError{}, err)
+	assert.Contains(t, out.String(), `level=error msg="can't parse go file: `)
+	assert.Contains(t, out.String(), `level=info msg="Found Approved license" file=vendor/github.com/fake/package/LICENSE license=BSD-3-Clause package=github.com/fake/package`)
+
+	initial := strict
+	defer func() { strict = initial }()
+
+	out.Reset()
+	_, err = newCli().ParseArgs([]string{"--strict", "check", "--no-color"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't walk imports: can't parse go file: ")
+		assert.Equal(t, exitIncompleteScan, exitCode(err))
+	}
+	assert.NotContains(t, out.String(), "Found Approved license")
+}
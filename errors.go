@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// Exit codes of wwhrd, documented in the README
+const (
+	exitOK = iota
+	exitNonApprovedLicense
+	exitConfigInvalid
+	exitIncompleteScan
+	exitError
+)
+
+// exitCode returns the exit code reporting err
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, wwhrd.ErrNonApprovedLicense):
+		return exitNonApprovedLicense
+	case errors.Is(err, wwhrd.ErrConfigInvalid):
+		return exitConfigInvalid
+	case errors.Is(err, wwhrd.ErrIncompleteScan):
+		return exitIncompleteScan
+	}
+	return exitError
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{wwhrd.ErrNonApprovedLicense, exitNonApprovedLicense},
+		{&wwhrd.ConfigError{Err: errors.New("yaml")}, exitConfigInvalid},
+		{fmt.Errorf("wrapped: %w", &wwhrd.IncompleteError{Errs: []error{errors.New("a")}}), exitIncompleteScan},
+		{errors.New("other"), exitError},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, exitCode(c.err))
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// exportColumns maps the column names accepted by list --columns to their value in a wwhrd.PackageResult
+var exportColumns = map[string]func(r wwhrd.PackageResult) string{
+	"package":    func(r wwhrd.PackageResult) string { return r.Package },
+	"module":     func(r wwhrd.PackageResult) string { return r.Module },
+	"version":    func(r wwhrd.PackageResult) string { return r.Version },
+	"license":    func(r wwhrd.PackageResult) string { return r.License },
+	"file":       func(r wwhrd.PackageResult) string { return r.File },
+	"source":     func(r wwhrd.PackageResult) string { return r.Source },
+	"coverage":   func(r wwhrd.PackageResult) string { return strconv.FormatFloat(r.Coverage, 'f', 1, 64) },
+	"detector":   func(r wwhrd.PackageResult) string { return r.Detector },
+	"confidence": func(r wwhrd.PackageResult) string { return strconv.FormatFloat(r.Confidence, 'f', 1, 64) },
+	"decision":   func(r wwhrd.PackageResult) string { return string(r.Decision) },
+	"reason":     func(r wwhrd.PackageResult) string { return r.Reason },
+	"direct": func(r wwhrd.PackageResult) string {
+		switch {
+		case r.Module == "" || !r.Required:
+			// nothing tells how the module is required
+			return ""
+		case r.Direct:
+			return "direct"
+		}
+		return "indirect"
+	},
+}
+
+// parseColumns splits a comma separated list of columns, validating each of them
+func parseColumns(columns string) ([]string, error) {
+	var cols []string
+	for _, c := range strings.Split(columns, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" {
+			continue
+		}
+		if _, ok := exportColumns[c]; !ok {
+			return nil, fmt.Errorf("unknown column %q", c)
+		}
+		cols = append(cols, c)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return cols, nil
+}
+
+// ExportResults writes results as delimiter separated values, with a header row
+func ExportResults(w io.Writer, results []wwhrd.PackageResult, columns []string, delimiter rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for _, r := range results {
+		for i, c := range columns {
+			row[i] = exportColumns[c](r)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColumns(t *testing.T) {
+	cols, err := parseColumns("package, License,,direct")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"package", "license", "direct"}, cols)
+
+	_, err = parseColumns("package,owner")
+	assert.EqualError(t, err, `unknown column "owner"`)
+
+	_, err = parseColumns(" , ")
+	assert.Error(t, err)
+
+	// every column is listed in the help of --columns
+	field, _ := reflect.TypeOf(List{}).FieldByName("Columns")
+	for col := range exportColumns {
+		assert.Contains(t, field.Tag.Get("description"), col)
+	}
+}
+
+func TestExportResults(t *testing.T) {
+	results := []wwhrd.PackageResult{
+		{Package: "github.com/a/b", Module: "github.com/a/b", Version: "v1.0.0", Required: true, Direct: true, License: "MIT", Coverage: 98.76, Decision: wwhrd.DecisionApproved},
+		{Package: "github.com/a/b,c", Module: "github.com/a/b", Version: "v1.0.0", Required: true, License: "MIT", Decision: wwhrd.DecisionApproved},
+		{Package: "github.com/d/e", License: "UNKNOWN"},
+		// the module is missing from go.mod
+		{Package: "github.com/f/g", Module: "github.com/f/g", Version: "v0.1.0", License: "MIT"},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, ExportResults(&out, results, []string{"package", "coverage", "direct"}, ','))
+	assert.Equal(t, "package,coverage,direct\ngithub.com/a/b,98.8,direct\n\"github.com/a/b,c\",0.0,indirect\ngithub.com/d/e,0.0,\ngithub.com/f/g,0.0,\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, ExportResults(&out, results[:1], []string{"package", "license", "decision"}, '\t'))
+	assert.Equal(t, "package\tlicense\tdecision\ngithub.com/a/b\tMIT\tapproved\n", out.String())
+
+	out.Reset()
+	reuse := wwhrd.PackageResult{Package: "github.com/h/i", License: "MIT", File: "vendor/github.com/h/i/LICENSES/MIT.txt", Source: "vendor/github.com/h/i/.reuse/dep5"}
+	assert.NoError(t, ExportResults(&out, []wwhrd.PackageResult{reuse}, []string{"file", "source"}, ','))
+	assert.Equal(t, "file,source\nvendor/github.com/h/i/LICENSES/MIT.txt,vendor/github.com/h/i/.reuse/dep5\n", out.String())
+}
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/emicklei/dot"
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// graphFormats maps the formats supported by the graph command to their file extension
+var graphFormats = map[string]string{
+	"dot":     "dot",
+	"mermaid": "mmd",
+	"graphml": "graphml",
+	"json":    "json",
+	"d2":      "d2",
+}
+
+// graphView is a snapshot of the dependency graph, ready to be rendered
+type graphView struct {
+	nodes []string
+	edges []wwhrd.Import
+	// results are used to style nodes by decision, when set
+	results map[string]wwhrd.PackageResult
+	// modules are used to cluster packages by module, when set
+	modules map[string]string
+}
+
+// newGraphView returns a snapshot of the graph to be rendered, when tree is set only the edges
+// of a breadth-first spanning tree from the root are kept
+func newGraphView(g *wwhrd.Graph, tree bool) *graphView {
+	v := &graphView{nodes: g.Packages()}
+
+	if !tree {
+		v.edges = g.Imports()
+		return v
+	}
+
+	edges := make(map[string][]wwhrd.Import)
+	for _, e := range g.Imports() {
+		edges[e.From] = append(edges[e.From], e)
+	}
+
+	// do a BFS on the graph, keeping the edge that first reaches each node
+	queue := []string{v.nodes[0]}
+	visited := map[string]bool{v.nodes[0]: true}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		for _, e := range edges[pkg] {
+			if !visited[e.To] {
+				v.edges = append(v.edges, e)
+				queue = append(queue, e.To)
+				visited[e.To] = true
+			}
+		}
+	}
+
+	return v
+}
+
+// graphFilter selects the part of the graph to render
+type graphFilter struct {
+	// depth is the maximum distance from the root, 0 means no limit
+	depth int
+	// focus keeps only the packages leading to these packages
+	focus []string
+	// violations keeps only the packages leading to a denied package
+	violations bool
+	// hideStd hides the packages living next to the standard library, under golang.org/x
+	hideStd bool
+	// hideAllowed hides the packages with an approved license
+	hideAllowed bool
+}
+
+// filter removes from the view the nodes not selected by f, along with their edges
+func (v *graphView) filter(f graphFilter) {
+	if len(v.nodes) == 0 {
+		return
+	}
+	root := v.nodes[0]
+
+	keep := make(map[string]bool, len(v.nodes))
+	for _, pkg := range v.nodes {
+		keep[pkg] = true
+	}
+
+	targets := append([]string(nil), f.focus...)
+	if f.violations {
+		for _, pkg := range v.nodes {
+			if v.results[pkg].Decision == wwhrd.DecisionDenied {
+				targets = append(targets, pkg)
+			}
+		}
+	}
+	if len(f.focus) > 0 || f.violations {
+		ancestors := v.ancestors(targets)
+		for pkg := range keep {
+			keep[pkg] = ancestors[pkg]
+		}
+	}
+
+	if f.depth > 0 {
+		dist := v.distances(root)
+		for pkg := range keep {
+			if d, ok := dist[pkg]; !ok || d > f.depth {
+				keep[pkg] = false
+			}
+		}
+	}
+
+	for pkg := range keep {
+		if pkg == root {
+			continue
+		}
+		if f.hideStd && isStdLike(pkg) {
+			keep[pkg] = false
+		}
+		if f.hideAllowed && v.results[pkg].Decision == wwhrd.DecisionApproved {
+			keep[pkg] = false
+		}
+	}
+
+	var nodes []string
+	for _, pkg := range v.nodes {
+		if keep[pkg] {
+			nodes = append(nodes, pkg)
+		}
+	}
+	var edges []wwhrd.Import
+	for _, e := range v.edges {
+		if keep[e.From] && keep[e.To] {
+			edges = append(edges, e)
+		}
+	}
+	v.nodes, v.edges = nodes, edges
+}
+
+// reverse keeps the packages depending on pkg and flips the edges, so the graph starts from pkg
+func (v *graphView) reverse(pkg string) {
+	ancestors := v.ancestors([]string{pkg})
+
+	nodes := []string{pkg}
+	for _, n := range v.nodes {
+		if ancestors[n] && n != pkg {
+			nodes = append(nodes, n)
+		}
+	}
+	var edges []wwhrd.Import
+	for _, e := range v.edges {
+		if ancestors[e.From] && ancestors[e.To] {
+			e.From, e.To = e.To, e.From
+			edges = append(edges, e)
+		}
+	}
+	v.nodes, v.edges = nodes, edges
+}
+
+// ancestors returns the set of packages leading to any of the targets, targets included
+func (v *graphView) ancestors(targets []string) map[string]bool {
+	importers := make(map[string][]string)
+	for _, e := range v.edges {
+		importers[e.To] = append(importers[e.To], e.From)
+	}
+
+	seen := make(map[string]bool)
+	queue := append([]string(nil), targets...)
+	for _, t := range targets {
+		seen[t] = true
+	}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, from := range importers[pkg] {
+			if !seen[from] {
+				seen[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+	return seen
+}
+
+// distances returns the length of the shortest import chain from pkg to every package it reaches
+func (v *graphView) distances(pkg string) map[string]int {
+	imports := make(map[string][]string)
+	for _, e := range v.edges {
+		imports[e.From] = append(imports[e.From], e.To)
+	}
+
+	dist := map[string]int{pkg: 0}
+	queue := []string{pkg}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, to := range imports[pkg] {
+			if _, ok := dist[to]; !ok {
+				dist[to] = dist[pkg] + 1
+				queue = append(queue, to)
+			}
+		}
+	}
+	return dist
+}
+
+// isStdLike reports whether pkg is one of the packages maintained alongside the standard library
+func isStdLike(pkg string) bool {
+	return pkg == "golang.org/x" || strings.HasPrefix(pkg, "golang.org/x/")
+}
+
+// nodeStyles are the fill colors used in the dependency graph, labelled after the decision
+// they stand for
+var nodeStyles = []struct {
+	label string
+	color string
+}{
+	{string(wwhrd.DecisionApproved), "palegreen"},
+	{string(wwhrd.DecisionDenied), "lightcoral"},
+	{string(wwhrd.DecisionExceptioned), "orange"},
+	{string(wwhrd.DecisionBaselined), "khaki"},
+	{styleUnknown, "lightgrey"},
+	{styleUnscanned, "white"},
+}
+
+const (
+	// styleUnknown is the style of the packages denied because their license is unknown
+	styleUnknown = "unknown"
+	// styleUnscanned is the style of the packages without results, e.g. the root
+	styleUnscanned = "unscanned"
+)
+
+// nodeStyle returns the label and color of the style used for the result of a package, ok being
+// false when the package has no result
+func nodeStyle(r wwhrd.PackageResult, ok bool) (string, string) {
+	label := string(r.Decision)
+	switch {
+	case !ok:
+		label = styleUnscanned
+	case r.Decision == wwhrd.DecisionDenied && r.License == wwhrd.UnknownLicense:
+		label = styleUnknown
+	}
+	for _, s := range nodeStyles {
+		if s.label == label {
+			return s.label, s.color
+		}
+	}
+	s := nodeStyles[len(nodeStyles)-1]
+	return s.label, s.color
+}
+
+// render writes the graph in the given format
+func (v *graphView) render(format string) (string, error) {
+	switch format {
+	case "dot":
+		return v.dot(), nil
+	case "mermaid":
+		return v.mermaid(), nil
+	case "graphml":
+		return v.graphML()
+	case "json":
+		return v.json()
+	case "d2":
+		return v.d2(), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q", format)
+}
+
+// dot renders the graph in DOT language, adding a legend when nodes are styled
+func (v *graphView) dot() string {
+	g := dot.NewGraph(dot.Directed)
+
+	nodes := make(map[string]dot.Node, len(v.nodes))
+	for _, pkg := range v.nodes {
+		parent := g
+		if m, ok := v.modules[pkg]; ok {
+			parent = g.Subgraph(m, dot.ClusterOption{})
+		}
+		n := parent.Node(pkg)
+		nodes[pkg] = n
+		if v.results == nil {
+			continue
+		}
+
+		n.Attr("style", "filled")
+		r, ok := v.results[pkg]
+		_, color := nodeStyle(r, ok)
+		n.Attr("fillcolor", color)
+		if ok {
+			n.Label(fmt.Sprintf("%s\n%s", pkg, r.License))
+		}
+	}
+
+	for _, e := range v.edges {
+		g.Edge(nodes[e.From], nodes[e.To]).Attr("tooltip", fmt.Sprintf("%s:%d", e.File, e.Line))
+	}
+
+	if v.results != nil {
+		legend := g.Subgraph("Legend", dot.ClusterOption{})
+		for _, s := range nodeStyles {
+			legend.Node("legend_"+s.label).Label(s.label).Box().Attr("style", "filled").Attr("fillcolor", s.color)
+		}
+	}
+
+	return g.String()
+}
+
+// label returns the text shown for a node, with its license when known
+func (v *graphView) label(pkg string) string {
+	if r, ok := v.results[pkg]; ok {
+		return pkg + "\n" + r.License
+	}
+	return pkg
+}
+
+// mermaid renders the graph as a Mermaid flowchart
+func (v *graphView) mermaid() string {
+	var b strings.Builder
+	escape := strings.NewReplacer(`"`, "#quot;", "\n", "<br/>")
+
+	ids := make(map[string]string, len(v.nodes))
+	clusters := make(map[string][]string)
+	var order []string
+	b.WriteString("flowchart LR\n")
+	for i, pkg := range v.nodes {
+		ids[pkg] = fmt.Sprintf("n%d", i)
+		if m, ok := v.modules[pkg]; ok {
+			if _, ok := clusters[m]; !ok {
+				order = append(order, m)
+			}
+			clusters[m] = append(clusters[m], pkg)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[pkg], escape.Replace(v.label(pkg)))
+	}
+	for i, m := range order {
+		fmt.Fprintf(&b, "\tsubgraph c%d[\"%s\"]\n", i, escape.Replace(m))
+		for _, pkg := range clusters[m] {
+			fmt.Fprintf(&b, "\t\t%s[\"%s\"]\n", ids[pkg], escape.Replace(v.label(pkg)))
+		}
+		b.WriteString("\tend\n")
+	}
+	for _, e := range v.edges {
+		fmt.Fprintf(&b, "\t%s --> %s\n", ids[e.From], ids[e.To])
+	}
+
+	if v.results != nil {
+		for _, s := range nodeStyles {
+			fmt.Fprintf(&b, "\tclassDef %s fill:%s\n", s.label, s.color)
+		}
+		for _, pkg := range v.nodes {
+			r, ok := v.results[pkg]
+			class, _ := nodeStyle(r, ok)
+			fmt.Fprintf(&b, "\tclass %s %s\n", ids[pkg], class)
+		}
+	}
+
+	return b.String()
+}
+
+// d2 renders the graph in the D2 language
+func (v *graphView) d2() string {
+	var b strings.Builder
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+
+	for _, pkg := range v.nodes {
+		fmt.Fprintf(&b, "%s: %s", quote(pkg), quote(v.label(pkg)))
+		if v.results != nil {
+			r, ok := v.results[pkg]
+			_, color := nodeStyle(r, ok)
+			fmt.Fprintf(&b, " {style.fill: %s}", quote(color))
+		}
+		b.WriteString("\n")
+	}
+	for _, e := range v.edges {
+		fmt.Fprintf(&b, "%s -> %s\n", quote(e.From), quote(e.To))
+	}
+
+	return b.String()
+}
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+type jsonNode struct {
+	ID       string         `json:"id"`
+	License  string         `json:"license,omitempty"`
+	Decision wwhrd.Decision `json:"decision,omitempty"`
+}
+
+type jsonEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// json renders the graph as a JSON document with nodes and edges lists
+func (v *graphView) json() (string, error) {
+	g := jsonGraph{Nodes: []jsonNode{}, Edges: []jsonEdge{}}
+	for _, pkg := range v.nodes {
+		r := v.results[pkg]
+		g.Nodes = append(g.Nodes, jsonNode{ID: pkg, License: r.License, Decision: r.Decision})
+	}
+	for _, e := range v.edges {
+		g.Edges = append(g.Edges, jsonEdge{From: e.From, To: e.To, File: e.File, Line: e.Line})
+	}
+
+	b, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphML renders the graph as a GraphML document
+func (v *graphView) graphML() (string, error) {
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "license", For: "node", Name: "license", Type: "string"},
+			{ID: "decision", For: "node", Name: "decision", Type: "string"},
+			{ID: "file", For: "edge", Name: "file", Type: "string"},
+			{ID: "line", For: "edge", Name: "line", Type: "int"},
+		},
+	}
+	doc.Graph.ID = "dependencies"
+	doc.Graph.EdgeDefault = "directed"
+
+	for _, pkg := range v.nodes {
+		n := graphMLNode{ID: pkg}
+		if r, ok := v.results[pkg]; ok {
+			n.Data = []graphMLData{{Key: "license", Value: r.License}, {Key: "decision", Value: string(r.Decision)}}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+	for _, e := range v.edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.From,
+			Target: e.To,
+			Data:   []graphMLData{{Key: "file", Value: e.File}, {Key: "line", Value: fmt.Sprint(e.Line)}},
+		})
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(b) + "\n", nil
+}
+
+// resultsByPackage indexes results by package
+func resultsByPackage(results []wwhrd.PackageResult) map[string]wwhrd.PackageResult {
+	m := make(map[string]wwhrd.PackageResult, len(results))
+	for _, r := range results {
+		m[r.Package] = r
+	}
+	return m
+}
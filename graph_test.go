@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStyleDotGraph(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestStyleDotGraph")
+	defer rm()
+
+	res, err := wwhrd.Scan(context.Background(), wwhrd.Options{
+		Root:              dir,
+		Config:            &wwhrd.Config{Denylist: []string{"BSD-3-Clause"}, Exceptions: []string{"github.com/fake/package"}},
+		CoverageThreshold: 75,
+	})
+	assert.NoError(t, err)
+	graph := res.Graph
+
+	view := newGraphView(graph, false)
+	view.results = resultsByPackage(res.Packages)
+	dotGraph := view.dot()
+	assert.Contains(t, dotGraph, `[fillcolor="orange",label="github.com/fake/package\nBSD-3-Clause",style="filled"]`)
+	assert.Contains(t, dotGraph, `[fillcolor="lightcoral",label="github.com/fake/nested/inside/a/package\nBSD-3-Clause",style="filled"]`)
+	assert.Contains(t, dotGraph, `[fillcolor="white",label="root",style="filled"]`)
+	assert.Contains(t, dotGraph, `label="Legend";`)
+
+	// baselined violations stand out from both approved and denied packages
+	r := view.results["github.com/fake/nested/inside/a/package"]
+	r.Decision = wwhrd.DecisionBaselined
+	view.results[r.Package] = r
+	dotGraph = view.dot()
+	assert.Contains(t, dotGraph, `[fillcolor="khaki",label="github.com/fake/nested/inside/a/package\nBSD-3-Clause",style="filled"]`)
+
+	// without results nodes are left bare
+	dotGraph = newGraphView(graph, false).dot()
+	assert.NotContains(t, dotGraph, "fillcolor")
+	assert.NotContains(t, dotGraph, "Legend")
+}
+
+func TestDotGraphEdges(t *testing.T) {
+	graph := wwhrd.NewGraph()
+	for _, pkg := range []string{"root", "a", "b", "c"} {
+		graph.AddPackage(pkg)
+	}
+	graph.AddImport(wwhrd.Import{From: "root", To: "a", File: "main.go", Line: 3})
+	graph.AddImport(wwhrd.Import{From: "root", To: "b", File: "main.go", Line: 4})
+	graph.AddImport(wwhrd.Import{From: "a", To: "c", File: "vendor/a/z.go", Line: 7})
+	graph.AddImport(wwhrd.Import{From: "a", To: "c", File: "vendor/a/a.go", Line: 9})
+	graph.AddImport(wwhrd.Import{From: "b", To: "c", File: "vendor/b/b.go", Line: 3})
+
+	// duplicated imports are merged, keeping the first one in file order
+	assert.Equal(t, []wwhrd.Import{
+		{From: "a", To: "c", File: "vendor/a/a.go", Line: 9},
+		{From: "b", To: "c", File: "vendor/b/b.go", Line: 3},
+		{From: "root", To: "a", File: "main.go", Line: 3},
+		{From: "root", To: "b", File: "main.go", Line: 4},
+	}, graph.Imports())
+
+	dotGraph := newGraphView(graph, false).dot()
+	assert.Contains(t, dotGraph, `n2->n4[tooltip="vendor/a/a.go:9"];`)
+	assert.Contains(t, dotGraph, `n3->n4[tooltip="vendor/b/b.go:3"];`)
+	assert.NotContains(t, dotGraph, `n4->`)
+
+	// the spanning tree only reaches c once
+	dotGraph = newGraphView(graph, true).dot()
+	assert.Contains(t, dotGraph, `->n4[tooltip="vendor/a/a.go:9"];`)
+	assert.NotContains(t, dotGraph, `vendor/b/b.go:3`)
+}
+
+func TestGraphViewRender(t *testing.T) {
+	v := &graphView{
+		nodes: []string{"root", "github.com/a/b"},
+		edges: []wwhrd.Import{{From: "root", To: "github.com/a/b", File: "main.go", Line: 3}},
+	}
+
+	cases := []struct {
+		format string
+		want   []string
+	}{
+		{"dot", []string{`n1->n2[tooltip="main.go:3"];`}},
+		{"mermaid", []string{"flowchart LR\n", "\tn1[\"github.com/a/b\"]\n", "\tn0 --> n1\n"}},
+		{"d2", []string{`"github.com/a/b": "github.com/a/b"` + "\n", `"root" -> "github.com/a/b"` + "\n"}},
+		{"json", []string{`"id": "github.com/a/b"`, `"from": "root",`, `"line": 3`}},
+		{"graphml", []string{`<node id="root"></node>`, `<edge source="root" target="github.com/a/b">`, `<data key="line">3</data>`}},
+	}
+
+	for _, c := range cases {
+		out, err := v.render(c.format)
+		assert.NoError(t, err, c.format)
+		for _, want := range c.want {
+			assert.Contains(t, out, want, c.format)
+		}
+	}
+
+	// nodes are styled by decision when results are set
+	v.results = map[string]wwhrd.PackageResult{"github.com/a/b": {Package: "github.com/a/b", License: "MIT", Decision: wwhrd.DecisionDenied}}
+	cases = []struct {
+		format string
+		want   []string
+	}{
+		{"mermaid", []string{"\tn1[\"github.com/a/b<br/>MIT\"]\n", "\tclass n0 unscanned\n", "\tclass n1 denied\n", "\tclassDef denied fill:lightcoral\n"}},
+		{"d2", []string{`"github.com/a/b": "github.com/a/b\nMIT" {style.fill: "lightcoral"}`}},
+		{"json", []string{`"license": "MIT",`, `"decision": "denied"`}},
+		{"graphml", []string{`<data key="decision">denied</data>`}},
+	}
+
+	for _, c := range cases {
+		out, err := v.render(c.format)
+		assert.NoError(t, err, c.format)
+		for _, want := range c.want {
+			assert.Contains(t, out, want, c.format)
+		}
+	}
+
+	// unknown licenses are not mistaken for denied ones
+	v.results["github.com/a/b"] = wwhrd.PackageResult{Package: "github.com/a/b", License: wwhrd.UnknownLicense, Decision: wwhrd.DecisionDenied}
+	out, err := v.render("d2")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"github.com/a/b": "github.com/a/b\nUNKNOWN" {style.fill: "lightgrey"}`)
+
+	_, err = v.render("svg")
+	assert.Error(t, err)
+}
+
+func TestGraphViewFilter(t *testing.T) {
+	newView := func() *graphView {
+		return &graphView{
+			nodes: []string{"root", "a", "b", "c", "golang.org/x/sys/unix", "d"},
+			edges: []wwhrd.Import{
+				{From: "root", To: "a"},
+				{From: "root", To: "b"},
+				{From: "a", To: "c"},
+				{From: "b", To: "golang.org/x/sys/unix"},
+				{From: "c", To: "d"},
+			},
+			results: map[string]wwhrd.PackageResult{
+				"a":                     {Decision: wwhrd.DecisionApproved},
+				"b":                     {Decision: wwhrd.DecisionApproved},
+				"c":                     {Decision: wwhrd.DecisionDenied},
+				"golang.org/x/sys/unix": {Decision: wwhrd.DecisionApproved},
+				"d":                     {Decision: wwhrd.DecisionExceptioned},
+			},
+		}
+	}
+
+	cases := []struct {
+		filter graphFilter
+		nodes  []string
+		edges  int
+	}{
+		{graphFilter{}, []string{"root", "a", "b", "c", "golang.org/x/sys/unix", "d"}, 5},
+		{graphFilter{depth: 1}, []string{"root", "a", "b"}, 2},
+		{graphFilter{focus: []string{"d"}}, []string{"root", "a", "c", "d"}, 3},
+		{graphFilter{violations: true}, []string{"root", "a", "c"}, 2},
+		{graphFilter{hideStd: true}, []string{"root", "a", "b", "c", "d"}, 4},
+		{graphFilter{hideAllowed: true}, []string{"root", "c", "d"}, 1},
+		{graphFilter{focus: []string{"d"}, depth: 2}, []string{"root", "a", "c"}, 2},
+	}
+
+	for i, c := range cases {
+		v := newView()
+		v.filter(c.filter)
+		assert.Equal(t, c.nodes, v.nodes, i)
+		assert.Len(t, v.edges, c.edges, i)
+	}
+}
+
+func TestGraphViewCluster(t *testing.T) {
+	v := &graphView{
+		nodes:   []string{"root", "github.com/a/b", "github.com/a/b/c"},
+		edges:   []wwhrd.Import{{From: "root", To: "github.com/a/b"}, {From: "github.com/a/b", To: "github.com/a/b/c"}},
+		modules: map[string]string{"github.com/a/b": "github.com/a/b", "github.com/a/b/c": "github.com/a/b"},
+	}
+
+	out := v.dot()
+	assert.Contains(t, out, "subgraph cluster_s2 {\n\t\tlabel=\"github.com/a/b\";\n\t\tn3[label=\"github.com/a/b\"];\n\t\tn4[label=\"github.com/a/b/c\"];")
+	assert.Contains(t, out, "n3->n4")
+
+	out = v.mermaid()
+	assert.Contains(t, out, "\tsubgraph c0[\"github.com/a/b\"]\n\t\tn1[\"github.com/a/b\"]\n\t\tn2[\"github.com/a/b/c\"]\n\tend\n")
+}
+
+func TestGraphViewReverse(t *testing.T) {
+	v := &graphView{
+		nodes: []string{"root", "a", "b", "c"},
+		edges: []wwhrd.Import{{From: "root", To: "a"}, {From: "root", To: "b"}, {From: "a", To: "c"}},
+	}
+
+	v.reverse("c")
+	assert.Equal(t, []string{"c", "root", "a"}, v.nodes)
+	assert.Equal(t, []wwhrd.Import{{From: "a", To: "root"}, {From: "c", To: "a"}}, v.edges)
+
+	// filters apply from the reversed package
+	v.filter(graphFilter{depth: 1})
+	assert.Equal(t, []string{"c", "a"}, v.nodes)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// incomplete collects the incomplete scans of a command, so that it carries on with partial
+// results and only fails once done
+type incomplete struct {
+	errs []error
+}
+
+// check records err when it reports an incomplete scan, any other error is returned
+func (i *incomplete) check(err error) error {
+	var ie *wwhrd.IncompleteError
+	if errors.As(err, &ie) {
+		i.errs = append(i.errs, ie.Errs...)
+		return nil
+	}
+	return err
+}
+
+// err returns an IncompleteError when an incomplete scan was recorded
+func (i *incomplete) err() error {
+	if len(i.errs) == 0 {
+		return nil
+	}
+	return &wwhrd.IncompleteError{Errs: i.errs}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncomplete(t *testing.T) {
+	var inc incomplete
+	assert.NoError(t, inc.check(nil))
+	assert.NoError(t, inc.err())
+
+	assert.NoError(t, inc.check(&wwhrd.IncompleteError{Errs: []error{errors.New("a")}}))
+	assert.NoError(t, inc.check(fmt.Errorf("wrapped: %w", &wwhrd.IncompleteError{Errs: []error{errors.New("b")}})))
+	assert.EqualError(t, inc.check(errors.New("c")), "c")
+
+	var ie *wwhrd.IncompleteError
+	if assert.True(t, errors.As(inc.err(), &ie)) {
+		assert.Equal(t, []error{errors.New("a"), errors.New("b")}, ie.Errs)
+	}
+}
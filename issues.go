@@ -0,0 +1,184 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+)
+
+// importCycles returns an import cycle for every group of vendored packages importing each other,
+// each cycle starts and ends with the same package
+func importCycles(g *wwhrd.Graph) [][]string {
+	adjacency := make(map[string][]string)
+	var nodes []string
+	for _, e := range g.Imports() {
+		if e.From == wwhrd.RootPackage {
+			continue
+		}
+		if _, ok := adjacency[e.From]; !ok {
+			nodes = append(nodes, e.From)
+		}
+		adjacency[e.From] = append(adjacency[e.From], e.To)
+	}
+
+	var cycles [][]string
+	for _, scc := range stronglyConnected(nodes, adjacency) {
+		if len(scc) < 2 {
+			continue
+		}
+		members := make(map[string]bool, len(scc))
+		for _, pkg := range scc {
+			members[pkg] = true
+		}
+		cycles = append(cycles, shortestCycle(scc[0], adjacency, members))
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+// stronglyConnected returns the strongly connected components of the graph using Tarjan's algorithm,
+// the members of each component are sorted
+func stronglyConnected(nodes []string, adjacency map[string][]string) [][]string {
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		sccs    [][]string
+	)
+
+	var connect func(pkg string)
+	connect = func(pkg string) {
+		index[pkg] = len(index)
+		lowlink[pkg] = index[pkg]
+		stack = append(stack, pkg)
+		onStack[pkg] = true
+
+		for _, to := range adjacency[pkg] {
+			if _, visited := index[to]; !visited {
+				connect(to)
+				if lowlink[to] < lowlink[pkg] {
+					lowlink[pkg] = lowlink[to]
+				}
+			} else if onStack[to] && index[to] < lowlink[pkg] {
+				lowlink[pkg] = index[to]
+			}
+		}
+
+		if lowlink[pkg] == index[pkg] {
+			var scc []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				scc = append(scc, top)
+				if top == pkg {
+					break
+				}
+			}
+			sort.Strings(scc)
+			sccs = append(sccs, scc)
+		}
+	}
+
+	for _, pkg := range nodes {
+		if _, visited := index[pkg]; !visited {
+			connect(pkg)
+		}
+	}
+
+	return sccs
+}
+
+// shortestCycle returns the shortest path from pkg back to itself, only going through members
+func shortestCycle(pkg string, adjacency map[string][]string, members map[string]bool) []string {
+	parent := make(map[string]string)
+	queue := []string{pkg}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, to := range adjacency[cur] {
+			if !members[to] {
+				continue
+			}
+			if to == pkg {
+				cycle := []string{pkg}
+				for p := cur; p != pkg; p = parent[p] {
+					cycle = append([]string{p}, cycle...)
+				}
+				return append([]string{pkg}, cycle...)
+			}
+			if _, seen := parent[to]; !seen {
+				parent[to] = cur
+				queue = append(queue, to)
+			}
+		}
+	}
+	return nil
+}
+
+// majorVersionSuffix matches the major version suffix of a module path, /vN or gopkg.in .vN
+var majorVersionSuffix = regexp.MustCompile(`(/v[0-9]+|\.v[0-9]+)$`)
+
+// duplicateModules returns the groups of module paths that only differ by major version or by case
+func duplicateModules(paths []string) [][]string {
+	groups := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		key := strings.ToLower(majorVersionSuffix.ReplaceAllString(p, ""))
+		groups[key] = append(groups[key], p)
+	}
+
+	var duplicates [][]string
+	for _, group := range groups {
+		if len(group) > 1 {
+			sort.Strings(group)
+			duplicates = append(duplicates, group)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i][0] < duplicates[j][0]
+	})
+	return duplicates
+}
+
+// warnImportCycles logs the import cycles among the vendored packages of the graph
+func warnImportCycles(g *wwhrd.Graph) {
+	for _, cycle := range importCycles(g) {
+		log.WithFields(log.Fields{
+			"cycle": strings.Join(cycle, " -> "),
+		}).Warn("Found import cycle")
+	}
+}
+
+// warnDuplicateModules logs the modules vendored under multiple paths, as listed in vendor/modules.txt.
+// Without it nothing tells modules apart from the packages they provide, so nothing is logged.
+func warnDuplicateModules(root string) error {
+	mods, err := wwhrd.ReadVendorModules(root)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, m := range mods.Modules {
+		paths = append(paths, m.Path)
+	}
+
+	for _, group := range duplicateModules(paths) {
+		log.WithFields(log.Fields{
+			"modules": strings.Join(group, ", "),
+		}).Warn("Found module vendored under multiple paths")
+	}
+
+	return nil
+}
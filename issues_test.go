@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCycles(t *testing.T) {
+	graph := wwhrd.NewGraph()
+	for _, e := range []wwhrd.Import{
+		{From: "root", To: "a"},
+		{From: "a", To: "b"},
+		{From: "b", To: "c"},
+		{From: "c", To: "a"},
+		{From: "c", To: "b"},
+		{From: "c", To: "d"},
+		{From: "d", To: "e"},
+		{From: "e", To: "d"},
+		{From: "e", To: "root"},
+	} {
+		graph.AddImport(e)
+	}
+
+	assert.Equal(t, [][]string{
+		{"a", "b", "c", "a"},
+		{"d", "e", "d"},
+	}, importCycles(graph))
+
+	assert.Empty(t, importCycles(wwhrd.NewGraph()))
+}
+
+func TestWarnImportCycles(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.TextFormatter{DisableColors: true})
+
+	dir, err := ioutil.TempDir("", "TestWarnImportCycles")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// github.com/a/b/c imports github.com/x/y, which imports github.com/a/b: github.com/a/b
+	// doesn't import github.com/x/y itself, there is no cycle
+	files := map[string]string{
+		"main.go":                      "package main\nimport (\n\t\"github.com/a/b\"\n\t\"github.com/a/b/c\"\n)\n",
+		"vendor/github.com/a/b/b.go":   "package b\n",
+		"vendor/github.com/a/b/c/c.go": "package c\nimport \"github.com/x/y\"\n",
+		"vendor/github.com/x/y/y.go":   "package y\nimport \"github.com/a/b\"\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	graph, err := wwhrd.Walk(context.Background(), wwhrd.Options{Root: dir})
+	assert.NoError(t, err)
+	warnImportCycles(graph)
+	assert.NotContains(t, out.String(), "Found import cycle")
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vendor/github.com/a/b/b.go"), []byte("package b\nimport \"github.com/a/b/c\"\n"), 0644))
+	graph, err = wwhrd.Walk(context.Background(), wwhrd.Options{Root: dir})
+	assert.NoError(t, err)
+	warnImportCycles(graph)
+	assert.Contains(t, out.String(), `level=warning msg="Found import cycle" cycle="github.com/a/b -> github.com/a/b/c -> github.com/x/y -> github.com/a/b"`)
+}
+
+func TestDuplicateModules(t *testing.T) {
+	assert.Equal(t, [][]string{
+		{"github.com/Sirupsen/logrus", "github.com/sirupsen/logrus"},
+		{"github.com/foo/bar", "github.com/foo/bar/v2", "github.com/foo/bar/v3"},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml.v3"},
+	}, duplicateModules([]string{
+		"gopkg.in/yaml.v2",
+		"github.com/foo/bar/v3",
+		"github.com/sirupsen/logrus",
+		"gopkg.in/yaml.v3",
+		"github.com/foo/bar",
+		"github.com/Sirupsen/logrus",
+		"github.com/foo/bar/v2",
+		"github.com/foo/bar",
+		"github.com/foo/barv2",
+	}))
+}
+
+func TestWarnDuplicateModules(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.TextFormatter{DisableColors: true})
+
+	dir, rm := mockGoPackageDir(t, "TestWarnDuplicateModules")
+	defer rm()
+
+	// without vendor/modules.txt, packages such as k8s.io/api/core and k8s.io/api/core/v1 are not
+	// mistaken for modules
+	assert.NoError(t, warnDuplicateModules(dir))
+	assert.NotContains(t, out.String(), "Found module vendored under multiple paths")
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), []byte(mockModulesTxt+"# github.com/Fake/package v1.0.0\ngithub.com/Fake/package\n"), 0644))
+	assert.NoError(t, warnDuplicateModules(dir))
+	assert.Contains(t, out.String(), `level=warning msg="Found module vendored under multiple paths" modules="github.com/Fake/package, github.com/fake/package"`)
+}
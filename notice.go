@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// NoticeFileNames used to search for NOTICE files, as required by Apache-2.0
+	NoticeFileNames = []string{
+		"NOTICE",
+		"NOTICE.md",
+		"NOTICE.markdown",
+		"NOTICE.txt",
+	}
+)
+
+// noticeFileNamesLowercase has all the entries of NoticeFileNames, lower cased and made a set
+// for fast case-insensitive matching.
+var noticeFileNamesLowercase = map[string]bool{}
+
+func init() {
+	for _, f := range NoticeFileNames {
+		noticeFileNamesLowercase[strings.ToLower(f)] = true
+	}
+}
+
+// defaultNoticeTemplate is used to render the notice file when no template is supplied
+const defaultNoticeTemplate = `THIRD-PARTY SOFTWARE NOTICES AND INFORMATION
+
+This software incorporates components from the projects listed below.
+{{- range .Licenses}}
+
+================================================================================
+{{.License}}
+{{- range .Packages}}
+  * {{.}}
+{{- end}}
+{{- if .Copyrights}}
+
+{{range .Copyrights}}{{.}}
+{{end}}
+{{- end}}
+--------------------------------------------------------------------------------
+
+{{.Text}}
+{{- end}}
+{{- range .Notices}}
+
+================================================================================
+NOTICE
+{{- range .Packages}}
+  * {{.}}
+{{- end}}
+--------------------------------------------------------------------------------
+
+{{.Text}}
+{{- end}}
+`
+
+// Notices is the data passed to the notice template
+type Notices struct {
+	Licenses []NoticeEntry
+	Notices  []NoticeEntry
+}
+
+// NoticeEntry is a unique license or NOTICE text, with the packages sharing it
+type NoticeEntry struct {
+	License    string
+	Packages   []string
+	Copyrights []string
+	Text       string
+}
+
+// GetNotices collects the license and NOTICE texts for the scanned packages, deduplicating identical texts.
+// Only the files isLicense tells are license files are embedded, as a license may have been declared
+// by another kind of file.
+func GetNotices(root string, results []wwhrd.PackageResult, isLicense func(file string) bool) (*Notices, error) {
+
+	vendor := root
+	if !strings.HasSuffix(vendor, "vendor") {
+		vendor = filepath.Join(vendor, "vendor")
+	}
+
+	licenses := newNoticeSet()
+	notices := newNoticeSet()
+
+	for _, r := range results {
+		files := r.Files
+		if len(files) == 0 && r.File != "" {
+			files = []string{r.File}
+		}
+		if len(files) == 0 {
+			log.Warnf("[%s] no license file found, skipping", r.Package)
+			continue
+		}
+
+		// NOTICE files sit next to the license files or in the package itself
+		var dirs []string
+		for _, f := range files {
+			if !isLicense(f) {
+				log.Warnf("[%s] %s is not a license file, skipping it", r.Package, f)
+				continue
+			}
+			file := filepath.Join(root, filepath.FromSlash(f))
+			text, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			licenses.add(r.License, r.Package, string(text))
+			dirs = appendDir(dirs, filepath.Dir(file))
+		}
+		dirs = appendDir(dirs, filepath.Join(vendor, r.Package))
+
+		for _, dir := range dirs {
+			files, err := findNoticeFiles(dir)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range files {
+				text, err := ioutil.ReadFile(f)
+				if err != nil {
+					return nil, err
+				}
+				notices.add("", r.Package, string(text))
+			}
+		}
+	}
+
+	return &Notices{
+		Licenses: licenses.entries,
+		Notices:  notices.entries,
+	}, nil
+}
+
+// RenderNotices renders notices using tmpl, or the built-in template if tmpl is empty
+func RenderNotices(w io.Writer, notices *Notices, tmpl string) error {
+	if tmpl == "" {
+		tmpl = defaultNoticeTemplate
+	}
+
+	t, err := template.New("notice").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, notices)
+}
+
+// noticeSet deduplicates texts while preserving the order they were first seen in, each package
+// being listed once per text
+type noticeSet struct {
+	entries []NoticeEntry
+	index   map[string]int
+	pkgs    map[string]map[string]bool
+}
+
+func newNoticeSet() *noticeSet {
+	return &noticeSet{index: make(map[string]int), pkgs: make(map[string]map[string]bool)}
+}
+
+func (s *noticeSet) add(license, pkg, text string) {
+	text = strings.TrimSpace(text)
+	if i, ok := s.index[text]; ok {
+		if !s.pkgs[text][pkg] {
+			s.pkgs[text][pkg] = true
+			s.entries[i].Packages = append(s.entries[i].Packages, pkg)
+		}
+		return
+	}
+	s.index[text] = len(s.entries)
+	s.pkgs[text] = map[string]bool{pkg: true}
+	s.entries = append(s.entries, NoticeEntry{
+		License:    license,
+		Packages:   []string{pkg},
+		Copyrights: copyrightLines(text),
+		Text:       text,
+	})
+}
+
+// appendDir appends dir to dirs unless already there
+func appendDir(dirs []string, dir string) []string {
+	for _, d := range dirs {
+		if d == dir {
+			return dirs
+		}
+	}
+	return append(dirs, dir)
+}
+
+func findNoticeFiles(dir string) ([]string, error) {
+	filesInDir, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range filesInDir {
+		if !f.IsDir() && noticeFileNamesLowercase[strings.ToLower(f.Name())] {
+			files = append(files, filepath.Join(dir, f.Name()))
+		}
+	}
+	return files, nil
+}
+
+// copyrightLines extracts the copyright statements from a license text
+func copyrightLines(text string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader([]byte(text)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lower := strings.ToLower(line)
+		// a year is what sets a copyright statement apart from the license prose mentioning copyright
+		if !strings.ContainsAny(line, "0123456789") {
+			continue
+		}
+		if strings.HasPrefix(lower, "copyright") || strings.HasPrefix(lower, "(c)") || strings.HasPrefix(line, "©") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNotices(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestGetNotices")
+	defer rm()
+
+	res, err := wwhrd.Scan(context.Background(), wwhrd.Options{Root: dir, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	isLicense, err := wwhrd.LicenseFileMatcher(nil)
+	assert.NoError(t, err)
+	notices, err := GetNotices(dir, res.Packages, isLicense)
+	assert.NoError(t, err)
+
+	// both packages ship the same license text, it must only appear once
+	if assert.Len(t, notices.Licenses, 1) {
+		assert.Equal(t, "BSD-3-Clause", notices.Licenses[0].License)
+		assert.Equal(t, []string{"github.com/fake/nested/inside/a/package", "github.com/fake/package"}, notices.Licenses[0].Packages)
+		assert.Equal(t, []string{"Copyright (c) 2016, Fabio Rapposelli"}, notices.Licenses[0].Copyrights)
+	}
+
+	if assert.Len(t, notices.Notices, 1) {
+		assert.Equal(t, []string{"github.com/fake/package"}, notices.Notices[0].Packages)
+	}
+
+	// files that aren't license texts are never embedded
+	res.Packages[1].File = "vendor/github.com/fake/package/mockpkg.go"
+	res.Packages[1].Files = []string{res.Packages[1].File}
+	notices, err = GetNotices(dir, res.Packages, isLicense)
+	assert.NoError(t, err)
+	if assert.Len(t, notices.Licenses, 1) {
+		assert.Equal(t, []string{"github.com/fake/nested/inside/a/package"}, notices.Licenses[0].Packages)
+	}
+
+	// the license texts of UNKNOWN packages are embedded as well
+	license := filepath.Join(dir, "vendor/github.com/fake/package/LICENSE")
+	assert.NoError(t, ioutil.WriteFile(license, []byte("All rights reserved by Fake.\n"), 0644))
+	res, err = wwhrd.Scan(context.Background(), wwhrd.Options{Root: dir, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	notices, err = GetNotices(dir, res.Packages, isLicense)
+	assert.NoError(t, err)
+	if assert.Len(t, notices.Licenses, 2) {
+		assert.Equal(t, wwhrd.UnknownLicense, notices.Licenses[1].License)
+		assert.Equal(t, []string{"github.com/fake/package"}, notices.Licenses[1].Packages)
+		assert.Equal(t, "All rights reserved by Fake.", notices.Licenses[1].Text)
+	}
+}
+
+func TestNoticeSet(t *testing.T) {
+	s := newNoticeSet()
+	s.add("MIT", "github.com/a/b", "MIT text")
+	s.add("ISC", "github.com/a/b", "ISC text")
+	s.add("MIT", "github.com/c/d", "MIT text\n")
+	s.add("MIT", "github.com/a/b", "MIT text")
+
+	// identical texts are merged, packages are listed once per text whatever the order
+	if assert.Len(t, s.entries, 2) {
+		assert.Equal(t, []string{"github.com/a/b", "github.com/c/d"}, s.entries[0].Packages)
+		assert.Equal(t, []string{"github.com/a/b"}, s.entries[1].Packages)
+	}
+}
+
+func TestRenderNotices(t *testing.T) {
+	notices := &Notices{
+		Licenses: []NoticeEntry{
+			{License: "MIT", Packages: []string{"github.com/a/b", "github.com/c/d"}, Text: "MIT text"},
+		},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, RenderNotices(&out, notices, ""))
+	assert.Contains(t, out.String(), "MIT\n  * github.com/a/b\n  * github.com/c/d\n")
+	assert.Contains(t, out.String(), "MIT text")
+
+	out.Reset()
+	assert.NoError(t, RenderNotices(&out, notices, `{{range .Licenses}}{{.License}}: {{join .Packages ","}}{{end}}`))
+	assert.Equal(t, "MIT: github.com/a/b,github.com/c/d", out.String())
+
+	assert.Error(t, RenderNotices(&out, notices, `{{range .Licenses}`))
+}
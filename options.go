@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// scanJobs is the number of concurrent workers, set with --jobs
+	scanJobs int
+	// strict stops walking and scanning at the first unreadable path, set with --strict
+	strict bool
+	// cacheDir overrides the directory of the license cache, set with --cache-dir
+	cacheDir string
+	// noCache disables the license cache, set with --no-cache
+	noCache bool
+)
+
+// setJobs sets the number of concurrent workers
+func setJobs(n int) error {
+	if n < 1 {
+		return fmt.Errorf("--jobs must be at least 1, got %d", n)
+	}
+	scanJobs = n
+	return nil
+}
+
+func setStrict() error {
+	strict = true
+	return nil
+}
+
+func setCacheDir(dir string) error {
+	cacheDir = dir
+	return nil
+}
+
+func setNoCache() error {
+	noCache = true
+	return nil
+}
+
+// cacheDirectory returns the directory holding the license cache
+func cacheDirectory() (string, error) {
+	if cacheDir != "" {
+		return cacheDir, nil
+	}
+	return wwhrd.DefaultCacheDir()
+}
+
+// scanOptions returns the options of a walk or a scan of root, honouring the global options
+func scanOptions(root string, config *wwhrd.Config, threshold float64, checkTest bool) wwhrd.Options {
+	opts := wwhrd.Options{
+		Root:              root,
+		Config:            config,
+		CoverageThreshold: threshold,
+		CheckTestFiles:    checkTest,
+		Jobs:              scanJobs,
+		KeepGoing:         !strict,
+	}
+
+	if !noCache {
+		dir, err := cacheDirectory()
+		if err != nil {
+			log.Debugf("License cache disabled: %s", err)
+		}
+		opts.CacheDir = dir
+	}
+
+	return opts
+}
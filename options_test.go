@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetJobs(t *testing.T) {
+	initial := scanJobs
+	defer func() { scanJobs = initial }()
+
+	assert.NoError(t, setJobs(3))
+	assert.Equal(t, 3, scanJobs)
+	assert.Error(t, setJobs(0))
+	assert.Equal(t, 3, scanJobs)
+}
+
+func TestScanOptions(t *testing.T) {
+	initialDir, initialNoCache := cacheDir, noCache
+	defer func() { cacheDir, noCache = initialDir, initialNoCache }()
+
+	assert.NoError(t, setCacheDir("cache"))
+	opts := scanOptions("root", nil, 75, true)
+	assert.Equal(t, "root", opts.Root)
+	assert.Equal(t, "cache", opts.CacheDir)
+	assert.True(t, opts.CheckTestFiles)
+
+	assert.NoError(t, setNoCache())
+	assert.Empty(t, scanOptions("root", nil, 75, false).CacheDir)
+}
@@ -0,0 +1,126 @@
+package wwhrd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/google/licensecheck"
+	log "github.com/sirupsen/logrus"
+)
+
+// cacheFormat is bumped whenever the layout of cached entries changes
+const cacheFormat = "1"
+
+// DefaultCacheDir returns the default directory of the license cache, in the user cache directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("can't find cache directory: %s", err)
+	}
+	return filepath.Join(dir, "wwhrd"), nil
+}
+
+// licenseCache stores the coverage of license texts on disk, content-addressed by the SHA-256 of
+// the text and of the settings that can change the result of the scan
+type licenseCache struct {
+	dir      string
+	settings string
+}
+
+// openCache returns the license cache in dir for the given threshold and custom licenses corpus,
+// or nil when dir is empty and the cache is disabled
+func openCache(dir string, threshold float64, corpus string) *licenseCache {
+	if dir == "" {
+		return nil
+	}
+
+	settings := fmt.Sprintf("format %s licensecheck %s threshold %v", cacheFormat, licensecheckVersion(), threshold)
+	if corpus != "" {
+		settings += " corpus " + corpus
+	}
+	return &licenseCache{
+		dir:      filepath.Join(dir, "licenses"),
+		settings: settings,
+	}
+}
+
+// licensecheckVersion returns the version of licensecheck the binary is built with
+func licensecheckVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/google/licensecheck" {
+				return dep.Version
+			}
+		}
+	}
+	return "unknown"
+}
+
+// path returns the file caching the coverage of text
+func (c *licenseCache) path(text []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.settings))
+	h.Write([]byte{0})
+	h.Write(text)
+	sum := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(c.dir, sum[:2], sum+".json")
+}
+
+// get returns the cached coverage of text
+func (c *licenseCache) get(text []byte) (licensecheck.Coverage, bool) {
+	var cov licensecheck.Coverage
+
+	b, err := ioutil.ReadFile(c.path(text))
+	if err != nil {
+		return cov, false
+	}
+	if err := json.Unmarshal(b, &cov); err != nil {
+		log.Debugf("Ignoring corrupted cache entry %s: %s", c.path(text), err)
+		return cov, false
+	}
+	return cov, true
+}
+
+// put caches the coverage of text, the entry is written to a temporary file first so that
+// concurrent runs never read a partial entry
+func (c *licenseCache) put(text []byte, cov licensecheck.Coverage) error {
+	b, err := json.Marshal(cov)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(text)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// CleanCache removes every entry of the license cache in dir, leaving anything else in dir untouched
+func CleanCache(dir string) error {
+	if err := os.RemoveAll(filepath.Join(dir, "licenses")); err != nil {
+		return fmt.Errorf("can't clean cache: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package wwhrd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/licensecheck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLicenseCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestLicenseCache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := openCache(dir, 75, "")
+	if !assert.NotNil(t, c) {
+		return
+	}
+
+	text := []byte(mockLicense)
+	_, ok := c.get(text)
+	assert.False(t, ok)
+
+	cov := licensecheck.Scan(text)
+	assert.NoError(t, c.put(text, cov))
+
+	cached, ok := c.get(text)
+	assert.True(t, ok)
+	assert.Equal(t, cov, cached)
+
+	// entries depend on the settings of the scan
+	_, ok = openCache(dir, 50, "").get(text)
+	assert.False(t, ok)
+
+	// corrupted entries are ignored
+	assert.NoError(t, ioutil.WriteFile(c.path(text), []byte("{"), 0644))
+	_, ok = c.get(text)
+	assert.False(t, ok)
+
+	other := filepath.Join(dir, "other")
+	assert.NoError(t, ioutil.WriteFile(other, nil, 0644))
+	assert.NoError(t, CleanCache(dir))
+	_, err = os.Stat(filepath.Join(dir, "licenses"))
+	assert.True(t, os.IsNotExist(err))
+	assert.FileExists(t, other)
+}
+
+func TestNoCache(t *testing.T) {
+	assert.Nil(t, openCache("", 75, ""))
+}
+
+func TestScanTextCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestScanTextCached")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	checker, err := licensecheck.NewScanner(licensecheck.BuiltinLicenses())
+	assert.NoError(t, err)
+
+	c := &licenseCache{dir: dir, settings: "test"}
+	text := []byte(mockLicense)
+	// a cached entry is trusted over the scanner
+	assert.NoError(t, c.put(text, licensecheck.Coverage{Percent: 100, Match: []licensecheck.Match{{ID: "MIT"}}}))
+
+	s := newLicenseScanner(checker, 75, defaultLicenseFiles(t), &scanErrors{})
+	s.cache = c
+	assert.Equal(t, "MIT", s.scanText(text).Match[0].ID)
+
+	s = newLicenseScanner(checker, 75, defaultLicenseFiles(t), &scanErrors{})
+	assert.Equal(t, "BSD-3-Clause", s.scanText(text).Match[0].ID)
+}
@@ -0,0 +1,98 @@
+package wwhrd
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+type OldConfig struct {
+	Allowlist  []string `yaml:"whitelist"`
+	Denylist   []string `yaml:"blacklist"`
+	Exceptions []string `yaml:"exceptions"`
+}
+
+type Config struct {
+	Allowlist      []string          `yaml:"allowlist"`
+	Denylist       []string          `yaml:"denylist"`
+	Exceptions     []string          `yaml:"exceptions"`
+	Justifications map[string]string `yaml:"justifications"`
+	// Licenses is a directory of custom license texts, see ReadCustomLicenses
+	Licenses string `yaml:"licenses"`
+	// Overrides declare the license of packages, keyed by package or, ending in /..., by package prefix
+	Overrides map[string]Override `yaml:"overrides"`
+	// LicenseFiles are glob patterns of license files, on top of FileNames and LicenseFilePatterns
+	LicenseFiles []string `yaml:"license_files"`
+}
+
+// ReadConfig parses a config, errors match ErrConfigInvalid
+func ReadConfig(config []byte) (*Config, error) {
+
+	t := Config{}
+	old := OldConfig{}
+
+	// Parse new format
+	if err := yaml.NewDecoder(bytes.NewReader(config)).Decode(&t); err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	// Parse old format
+	if err := yaml.NewDecoder(bytes.NewReader(config)).Decode(&old); err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	t.Allowlist = append(t.Allowlist, old.Allowlist...)
+	t.Denylist = append(t.Denylist, old.Denylist...)
+	t.Exceptions = append(t.Exceptions, old.Exceptions...)
+
+	return &t, nil
+}
+
+// ReadConfigFile reads and parses the config file at path, use - for stdin, errors match ErrConfigInvalid.
+// The custom licenses directory is relative to the directory of the config file.
+func ReadConfigFile(path string) (*Config, error) {
+	var config []byte
+
+	if path == "-" {
+		mf := bufio.NewReader(os.Stdin)
+		var err error
+		config, err = ioutil.ReadAll(mf)
+		if err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+	} else {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, &ConfigError{Err: err}
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+
+		config, err = ioutil.ReadAll(f)
+		if err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+
+		if err = f.Close(); err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+
+	}
+
+	t, err := ReadConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Licenses != "" && path != "-" && !filepath.IsAbs(t.Licenses) {
+		t.Licenses = filepath.Join(filepath.Dir(path), t.Licenses)
+	}
+
+	return t, nil
+}
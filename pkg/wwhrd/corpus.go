@@ -0,0 +1,105 @@
+package wwhrd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// lreTokens are the sequences with a meaning in license regular expressions, plain license texts
+// have them split in two, punctuation being ignored when matching
+var lreTokens = []string{"((", "))", "||", "??", "__", "//**"}
+
+// ReadCustomLicenses reads the custom license texts of dir, each file holds a license whose ID is
+// the name of the file without its extension. Files with the .lre extension are license regular
+// expressions, as found in the licensecheck corpus, any other file is plain text. IDs must be
+// unique and distinct from the IDs of the builtin licenses, errors are ConfigErrors.
+func ReadCustomLicenses(dir string) ([]licensecheck.License, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, &ConfigError{Err: fmt.Errorf("can't read custom licenses: %s", err)}
+	}
+
+	builtin := make(map[string]bool)
+	for _, l := range licensecheck.BuiltinLicenses() {
+		builtin[l.ID] = true
+	}
+
+	var licenses []licensecheck.License
+	seen := make(map[string]string)
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+
+		ext := filepath.Ext(f.Name())
+		id := strings.TrimSuffix(f.Name(), ext)
+		if other, ok := seen[id]; ok {
+			return nil, &ConfigError{Err: fmt.Errorf("custom licenses %s and %s have the same ID %q", other, f.Name(), id)}
+		}
+		if tokens := tokenizeExpression(id); len(tokens) != 1 || !isLicenseID(id) {
+			return nil, &ConfigError{Err: fmt.Errorf("custom license %s has an ID %q that can't be used in license expressions", f.Name(), id)}
+		}
+		if builtin[id] {
+			return nil, &ConfigError{Err: fmt.Errorf("custom license %s has the ID of a builtin license %q", f.Name(), id)}
+		}
+		seen[id] = f.Name()
+
+		text, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, &ConfigError{Err: fmt.Errorf("can't read custom license: %s", err)}
+		}
+
+		lre := string(text)
+		if ext != ".lre" {
+			lre = plainLRE(lre)
+		}
+		licenses = append(licenses, licensecheck.License{ID: id, LRE: lre})
+	}
+
+	sort.Slice(licenses, func(i, j int) bool {
+		return licenses[i].ID < licenses[j].ID
+	})
+	return licenses, nil
+}
+
+// plainLRE turns a plain license text into a license regular expression matching it
+func plainLRE(text string) string {
+	for changed := true; changed; {
+		changed = false
+		for _, tok := range lreTokens {
+			if strings.Contains(text, tok) {
+				text = strings.Replace(text, tok, tok[:1]+" "+tok[1:], -1)
+				changed = true
+			}
+		}
+	}
+	return text
+}
+
+// newChecker returns the licensecheck scanner matching the builtin licenses and the custom ones
+func newChecker(custom []licensecheck.License) (*licensecheck.Scanner, error) {
+	checker, err := licensecheck.NewScanner(append(licensecheck.BuiltinLicenses(), custom...))
+	if err != nil {
+		return nil, fmt.Errorf("can't initialize license scanner: %s", err)
+	}
+	return checker, nil
+}
+
+// corpusDigest identifies a set of custom licenses, so that cached scans don't outlive them
+func corpusDigest(custom []licensecheck.License) string {
+	if len(custom) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, l := range custom {
+		fmt.Fprintf(h, "%s\x00%s\x00", l.ID, l.LRE)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,108 @@
+package wwhrd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mockEULA = `ACME Corporation End User License Agreement
+
+This software is the property of the ACME Corporation and is licensed, not
+sold, to the recipient for use within its own organisation only. The recipient
+shall not distribute, sublicense or otherwise make this software available to
+any third party without the prior written consent of the ACME Corporation.
+(( All rights not expressly granted herein are reserved by ACME Corporation. ))
+`
+
+func TestReadCustomLicenses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestReadCustomLicenses")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ACME-EULA.txt"), []byte(mockEULA), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ACME-MIT.lre"), []byte("Permission to use this software is granted to __3__ customers\n((provided || given))\nthat this notice is kept\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte("ignored"), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0755))
+
+	licenses, err := ReadCustomLicenses(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, licenses, 2) {
+		assert.Equal(t, "ACME-EULA", licenses[0].ID)
+		// plain texts can't be mistaken for license regular expressions
+		assert.Contains(t, licenses[0].LRE, "( ( All rights")
+		assert.Equal(t, "ACME-MIT", licenses[1].ID)
+		assert.Equal(t, "Permission to use this software is granted to __3__ customers\n((provided || given))\nthat this notice is kept\n", licenses[1].LRE)
+	}
+
+	_, err = newChecker(licenses)
+	assert.NoError(t, err)
+	assert.NotEqual(t, corpusDigest(licenses), corpusDigest(licenses[:1]))
+	assert.Empty(t, corpusDigest(nil))
+
+	_, err = ReadCustomLicenses(filepath.Join(dir, "NONEXISTENT"))
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+
+	// IDs are unique, whatever the extension
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ACME-EULA.lre"), []byte(mockEULA), 0644))
+	_, err = ReadCustomLicenses(dir)
+	assert.EqualError(t, err, `can't read config file: custom licenses ACME-EULA.lre and ACME-EULA.txt have the same ID "ACME-EULA"`)
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "ACME-EULA.lre")))
+
+	// builtin licenses can't be redefined
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "MIT.txt"), []byte(mockEULA), 0644))
+	_, err = ReadCustomLicenses(dir)
+	assert.EqualError(t, err, `can't read config file: custom license MIT.txt has the ID of a builtin license "MIT"`)
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "MIT.txt")))
+
+	// nor can IDs clash with the syntax of license expressions
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ACME EULA.txt"), []byte(mockEULA), 0644))
+	_, err = ReadCustomLicenses(dir)
+	assert.EqualError(t, err, `can't read config file: custom license ACME EULA.txt has an ID "ACME EULA" that can't be used in license expressions`)
+}
+
+func TestPlainLRE(t *testing.T) {
+	assert.Equal(t, "( ( ( a | | b ) ) ) ? ? _ _1_ _ / /**", plainLRE("((( a || b ))) ?? __1__ //**"))
+}
+
+func TestScanCustomLicenses(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanCustomLicenses")
+	defer rm()
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "licenses"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "licenses", "ACME-EULA.txt"), []byte(mockEULA), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vendor/github.com/fake/package/LICENSE"), []byte(mockEULA), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".wwhrd.yml"), []byte("allowlist:\n  - ACME-EULA\nlicenses: licenses\n"), 0644))
+
+	config, err := ReadConfigFile(filepath.Join(dir, ".wwhrd.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "licenses"), config.Licenses)
+
+	res, err := Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "github.com/fake/package", res.Packages[1].Package)
+		assert.Equal(t, "ACME-EULA", res.Packages[1].License)
+		assert.Equal(t, DecisionApproved, res.Packages[1].Decision)
+	}
+
+	// without the custom licenses, the EULA is unknown
+	res, err = Scan(context.Background(), Options{Root: dir, Config: &Config{Allowlist: []string{"ACME-EULA"}}, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	assert.Equal(t, UnknownLicense, res.Packages[1].License)
+
+	_, err = Scan(context.Background(), Options{Root: dir, Config: &Config{Licenses: filepath.Join(dir, "NONEXISTENT")}})
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+
+	// so are invalid license regular expressions
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "licenses", "BROKEN.lre"), []byte("__3__ wildcard first\n"), 0644))
+	_, err = Scan(context.Background(), Options{Root: dir, Config: config})
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+}
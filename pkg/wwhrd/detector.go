@@ -0,0 +1,99 @@
+package wwhrd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LicenseFiles is the name of the builtin detector, matching the license files of a package
+// and of its parent directories against the licensecheck corpus
+const LicenseFiles = "license-file"
+
+// Package is a vendored package whose license is to be detected
+type Package struct {
+	// Path is the import path of the package
+	Path string
+	// Dir is the directory of the package
+	Dir string
+}
+
+// Detection is the license of a package, as found by a Detector
+type Detection struct {
+	// License is the identifier of the license
+	License string
+	// File is the file the license was found in, if any
+	File string
+	// Files are all the license texts the license was derived from, File included, when there
+	// are several of them License is an SPDX expression combining their licenses with AND
+	Files []string
+	// Source is the file declaring the license when it isn't a license text, such as a
+	// .reuse/dep5 file
+	Source string
+	// Confidence ranges from 0 to 100, for license files it is the percentage of the file
+	// matching the license
+	Confidence float64
+	// Detector is the name of the detector that found the license, filled in by Chain when empty
+	Detector string
+	// Reason is why the license was declared rather than detected, for overrides
+	Reason string
+	// Winner is set by Chain on the detection it picked, the one of its first detector that could tell
+	Winner bool
+	// Detections are set by Chain on the winner, they are the detections of all of its detectors,
+	// in order and the winner included
+	Detections []Detection
+}
+
+// Detector detects the license of a package, it must be safe for concurrent use
+type Detector interface {
+	// Name identifies the detector in the results
+	Name() string
+	// Detect returns the license of pkg, or nil when the detector can't tell
+	Detect(pkg Package) (*Detection, error)
+}
+
+// Chain returns a detector asking each of detectors in turn, the first detection wins and
+// carries all of them in Detections
+func Chain(detectors ...Detector) Detector {
+	return chain(detectors)
+}
+
+type chain []Detector
+
+func (c chain) Name() string {
+	names := make([]string, 0, len(c))
+	for _, d := range c {
+		names = append(names, d.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (c chain) Detect(pkg Package) (*Detection, error) {
+	var all []Detection
+	for _, d := range c {
+		det, err := d.Detect(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("%s detector: %w", d.Name(), err)
+		}
+		switch {
+		case det == nil:
+		case len(det.Detections) > 0:
+			// nested chains are flattened
+			all = append(all, det.Detections...)
+		default:
+			if det.Detector == "" {
+				det.Detector = d.Name()
+			}
+			all = append(all, *det)
+		}
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+
+	for i := range all {
+		all[i].Winner = i == 0
+	}
+	winner := all[0]
+	winner.Detections = all
+	return &winner, nil
+}
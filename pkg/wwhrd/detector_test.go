@@ -0,0 +1,109 @@
+package wwhrd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDetector detects the licenses of a fixed set of packages
+type mockDetector struct {
+	name     string
+	licenses map[string]string
+	err      error
+}
+
+func (m *mockDetector) Name() string {
+	return m.name
+}
+
+func (m *mockDetector) Detect(pkg Package) (*Detection, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if lic, ok := m.licenses[pkg.Path]; ok {
+		return &Detection{License: lic, Confidence: 50}, nil
+	}
+	return nil, nil
+}
+
+func TestChain(t *testing.T) {
+	first := &mockDetector{name: "first", licenses: map[string]string{"a": "MIT"}}
+	second := &mockDetector{name: "second", licenses: map[string]string{"a": "Apache-2.0", "b": "ISC"}}
+	c := Chain(first, second)
+
+	assert.Equal(t, "first,second", c.Name())
+
+	// every detection is returned along with the winner
+	det, err := c.Detect(Package{Path: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Detection{License: "MIT", Confidence: 50, Detector: "first", Winner: true, Detections: []Detection{
+		{License: "MIT", Confidence: 50, Detector: "first", Winner: true},
+		{License: "Apache-2.0", Confidence: 50, Detector: "second"},
+	}}, det)
+
+	det, err = c.Detect(Package{Path: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "second", det.Detector)
+	assert.Len(t, det.Detections, 1)
+
+	det, err = c.Detect(Package{Path: "c"})
+	assert.NoError(t, err)
+	assert.Nil(t, det)
+
+	// nested chains keep the name of the detector that found the license
+	det, err = Chain(&mockDetector{name: "empty"}, c).Detect(Package{Path: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "second", det.Detector)
+
+	// the detections of nested chains are flattened
+	det, err = Chain(c, &mockDetector{name: "third", licenses: map[string]string{"a": "ISC"}}).Detect(Package{Path: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "first", det.Detector)
+	if assert.Len(t, det.Detections, 3) {
+		assert.True(t, det.Detections[0].Winner)
+		assert.Equal(t, []string{"first", "second", "third"}, []string{det.Detections[0].Detector, det.Detections[1].Detector, det.Detections[2].Detector})
+		assert.False(t, det.Detections[1].Winner || det.Detections[2].Winner)
+		assert.Empty(t, det.Detections[2].Detections)
+	}
+
+	boom := errors.New("boom")
+	_, err = Chain(&mockDetector{name: "broken", err: boom}, second).Detect(Package{Path: "a"})
+	assert.EqualError(t, err, "broken detector: boom")
+	assert.True(t, errors.Is(err, boom))
+}
+
+func TestScanDetectors(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanDetectors")
+	defer rm()
+
+	detector := &mockDetector{name: "mock", licenses: map[string]string{"github.com/fake/package": "MIT"}}
+	res, err := Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75, Detectors: []Detector{detector}})
+	assert.NoError(t, err)
+
+	if assert.Len(t, res.Packages, 2) {
+		// detectors are asked before the license files
+		nested, fake := res.Packages[0], res.Packages[1]
+		assert.Equal(t, "BSD-3-Clause", nested.License)
+		assert.Equal(t, LicenseFiles, nested.Detector)
+		assert.Equal(t, nested.Coverage, nested.Confidence)
+		assert.Equal(t, "MIT", fake.License)
+		assert.Equal(t, "mock", fake.Detector)
+		assert.Equal(t, float64(50), fake.Confidence)
+		assert.Empty(t, fake.File)
+	}
+
+	boom := errors.New("boom")
+	broken := &mockDetector{name: "broken", err: boom}
+	_, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75, Detectors: []Detector{broken}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "broken detector: boom")
+		assert.True(t, errors.Is(err, boom))
+	}
+
+	res, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75, Detectors: []Detector{broken}, KeepGoing: true})
+	assert.True(t, errors.Is(err, ErrIncompleteScan))
+	assert.Empty(t, res.Packages)
+}
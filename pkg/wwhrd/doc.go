@@ -0,0 +1,25 @@
+/*
+Package wwhrd checks the licenses of the vendored dependencies of a Go project.
+
+Scan walks the imports of the project, detects the license of every imported vendored package
+and evaluates it against a Config:
+
+	config, err := wwhrd.ReadConfigFile(".wwhrd.yml")
+	if err != nil {
+		return err
+	}
+
+	res, err := wwhrd.Scan(ctx, wwhrd.Options{Root: ".", Config: config, CoverageThreshold: 75})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range res.Denied() {
+		fmt.Printf("%s: %s\n", p.Package, p.License)
+	}
+
+Walk only builds the import graph of the project, without detecting licenses.
+
+Errors match ErrNonApprovedLicense, ErrConfigInvalid and ErrIncompleteScan with errors.Is.
+*/
+package wwhrd
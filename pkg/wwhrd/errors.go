@@ -0,0 +1,32 @@
+package wwhrd
+
+import (
+	"errors"
+)
+
+var (
+	// ErrConfigInvalid is matched by the errors of config files that can't be read or parsed
+	ErrConfigInvalid = errors.New("invalid config")
+	// ErrNonApprovedLicense is returned by Result.Check when a package has a non-approved license
+	ErrNonApprovedLicense = errors.New("Non-Approved license found")
+	// ErrIncompleteScan is matched by IncompleteError, returned when paths could not be walked or scanned,
+	// and by the error the walk or scan stops at when not keeping going
+	ErrIncompleteScan = errors.New("incomplete scan")
+)
+
+// ConfigError reports a config file that can't be read or parsed, it matches ErrConfigInvalid
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return "can't read config file: " + e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrConfigInvalid
+}
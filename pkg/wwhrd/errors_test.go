@@ -0,0 +1,18 @@
+package wwhrd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigErrors(t *testing.T) {
+	_, err := ReadConfigFile("NONEXISTENT")
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+
+	_, err = ReadConfig([]byte("allowlist: [\n"))
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+	var ce *ConfigError
+	assert.True(t, errors.As(err, &ce))
+}
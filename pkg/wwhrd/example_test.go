@@ -0,0 +1,107 @@
+package wwhrd_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// mockProject lays out a project importing a single vendored package, licensed under the MIT license
+func mockProject() (string, error) {
+	dir, err := ioutil.TempDir("", "ExampleScan")
+	if err != nil {
+		return "", err
+	}
+
+	files := map[string]string{
+		"main.go": "package main\nimport \"github.com/fake/package\"\nfunc main() {}\n",
+		"vendor/github.com/fake/package/package.go": "package fake\n",
+		"vendor/github.com/fake/package/LICENSE":    mitLicense,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+func ExampleScan() {
+	dir, err := mockProject()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	config, err := wwhrd.ReadConfig([]byte("allowlist:\n  - Apache-2.0\n"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	res, err := wwhrd.Scan(context.Background(), wwhrd.Options{Root: dir, Config: config, CoverageThreshold: 75})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, p := range res.Packages {
+		fmt.Printf("%s %s %s\n", p.Package, p.License, p.Decision)
+	}
+	fmt.Println(res.Check())
+	// Output:
+	// github.com/fake/package MIT denied
+	// Non-Approved license found
+}
+
+func ExampleWalk() {
+	dir, err := mockProject()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	graph, err := wwhrd.Walk(context.Background(), wwhrd.Options{Root: dir})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, imp := range graph.Imports() {
+		fmt.Printf("%s -> %s (%s:%d)\n", imp.From, imp.To, imp.File, imp.Line)
+	}
+	// Output:
+	// root -> github.com/fake/package (main.go:2)
+}
+
+var mitLicense = `MIT License
+
+Copyright (c) 2016 The Fake Authors
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
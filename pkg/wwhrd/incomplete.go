@@ -0,0 +1,78 @@
+package wwhrd
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// IncompleteError reports the paths that could not be walked or scanned, the results computed
+// without them are returned along with it. It matches ErrIncompleteScan.
+type IncompleteError struct {
+	Errs []error
+}
+
+func (e *IncompleteError) Is(target error) bool {
+	return target == ErrIncompleteScan
+}
+
+func (e *IncompleteError) Error() string {
+	if len(e.Errs) == 1 {
+		return fmt.Sprintf("incomplete scan: %s", e.Errs[0])
+	}
+	return fmt.Sprintf("incomplete scan: %d paths could not be walked or scanned", len(e.Errs))
+}
+
+// stopError reports the path a walk or scan stopped at when not keeping going past unreadable
+// paths, no result is returned along with it. It matches ErrIncompleteScan.
+type stopError struct {
+	err error
+}
+
+func (e *stopError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stopError) Unwrap() error {
+	return e.err
+}
+
+func (e *stopError) Is(target error) bool {
+	return target == ErrIncompleteScan
+}
+
+// scanErrors collects the errors met while walking or scanning, it can be used concurrently
+type scanErrors struct {
+	keepGoing bool
+
+	mu   sync.Mutex
+	errs []error
+}
+
+func newScanErrors(keepGoing bool) *scanErrors {
+	return &scanErrors{keepGoing: keepGoing}
+}
+
+// add records err, it is returned when the walk or scan must stop, as a stopError
+func (s *scanErrors) add(err error) error {
+	if !s.keepGoing {
+		return &stopError{err: err}
+	}
+
+	log.Error(err.Error())
+	s.mu.Lock()
+	s.errs = append(s.errs, err)
+	s.mu.Unlock()
+	return nil
+}
+
+// err returns an IncompleteError when errors were recorded
+func (s *scanErrors) err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return &IncompleteError{Errs: append([]error(nil), s.errs...)}
+}
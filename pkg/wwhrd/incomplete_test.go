@@ -0,0 +1,20 @@
+package wwhrd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanErrors(t *testing.T) {
+	failFast := &scanErrors{}
+	assert.EqualError(t, failFast.add(errors.New("can't read directory: a")), "can't read directory: a")
+	assert.NoError(t, failFast.err())
+
+	keepGoing := &scanErrors{keepGoing: true}
+	assert.NoError(t, keepGoing.add(errors.New("can't read directory: a")))
+	assert.EqualError(t, keepGoing.err(), "incomplete scan: can't read directory: a")
+	assert.NoError(t, keepGoing.add(errors.New("can't read directory: b")))
+	assert.EqualError(t, keepGoing.err(), "incomplete scan: 2 paths could not be walked or scanned")
+}
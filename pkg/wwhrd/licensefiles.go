@@ -0,0 +1,137 @@
+package wwhrd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LicenseFilePatterns are the glob patterns of license files searched on top of FileNames, relative
+// to the package directory. "LICENSES/*" follows the REUSE convention of keeping the license texts
+// of a project in a LICENSES directory. Changes are taken into account by the next scan.
+var LicenseFilePatterns = []string{
+	"LICENSES/*",
+}
+
+// licenseFiles matches license files case-insensitively, by name or by glob pattern
+type licenseFiles struct {
+	names map[string]bool
+	// globs are split in path elements, lower cased
+	globs [][]string
+}
+
+// newLicenseFiles returns the matcher of FileNames, LicenseFilePatterns and of the extra patterns,
+// as understood by path.Match
+func newLicenseFiles(patterns []string) (*licenseFiles, error) {
+	l := &licenseFiles{names: make(map[string]bool)}
+	for _, f := range FileNames {
+		l.names[strings.ToLower(f)] = true
+	}
+
+	for _, p := range append(append([]string(nil), LicenseFilePatterns...), patterns...) {
+		p = strings.ToLower(path.Clean(filepath.ToSlash(p)))
+		if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("license file pattern %q is outside of the package directory", p)
+		}
+		elems := strings.Split(p, "/")
+		for _, e := range elems {
+			if _, err := path.Match(e, ""); err != nil {
+				return nil, fmt.Errorf("invalid license file pattern %q: %s", p, err)
+			}
+		}
+		l.globs = append(l.globs, elems)
+	}
+	return l, nil
+}
+
+// LicenseFileMatcher returns a function telling if a slash separated path is a license file, as
+// searched by Scan with config, whatever the directory of the package it is found from. Config
+// may be nil.
+func LicenseFileMatcher(config *Config) (func(file string) bool, error) {
+	var patterns []string
+	if config != nil {
+		patterns = config.LicenseFiles
+	}
+	l, err := newLicenseFiles(patterns)
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	return func(file string) bool {
+		elems := strings.Split(path.Clean(file), "/")
+		for i := len(elems) - 1; i >= 0; i-- {
+			if l.match(strings.Join(elems[i:], "/")) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// match tells if the slash separated path, relative to the package directory, is a license file
+func (l *licenseFiles) match(rel string) bool {
+	rel = strings.ToLower(rel)
+	if l.names[rel] {
+		return true
+	}
+	elems := strings.Split(rel, "/")
+	for _, g := range l.globs {
+		if len(g) == len(elems) && matchElems(g, elems) {
+			return true
+		}
+	}
+	return false
+}
+
+// descend tells if license files may be found under the slash separated directory
+func (l *licenseFiles) descend(rel string) bool {
+	elems := strings.Split(strings.ToLower(rel), "/")
+	for _, g := range l.globs {
+		if len(g) > len(elems) && matchElems(g[:len(elems)], elems) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchElems(globs, elems []string) bool {
+	for i, g := range globs {
+		if ok, _ := path.Match(g, elems[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the license files of dir, as slash separated paths relative to dir, in lexical order
+func (l *licenseFiles) find(dir string) ([]string, error) {
+	return l.findIn(dir, "")
+}
+
+func (l *licenseFiles) findIn(dir, prefix string) ([]string, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(dir, filepath.FromSlash(prefix)))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		rel := path.Join(prefix, e.Name())
+		if !e.IsDir() {
+			if l.match(rel) {
+				files = append(files, rel)
+			}
+			continue
+		}
+		if l.descend(rel) {
+			sub, err := l.findIn(dir, rel)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, sub...)
+		}
+	}
+	return files, nil
+}
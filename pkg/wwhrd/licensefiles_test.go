@@ -0,0 +1,163 @@
+package wwhrd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// defaultLicenseFiles returns the matcher of FileNames and LicenseFilePatterns
+func defaultLicenseFiles(t *testing.T) *licenseFiles {
+	l, err := newLicenseFiles(nil)
+	assert.NoError(t, err)
+	return l
+}
+
+func TestLicenseFiles(t *testing.T) {
+	l, err := newLicenseFiles([]string{"LICENSE.*", "COPYRIGHT", "third_party/*/LICENSE"})
+	assert.NoError(t, err)
+
+	for rel, want := range map[string]bool{
+		"LICENSE":                    true,
+		"license.bsd":                true,
+		"COPYRIGHT":                  true,
+		"LICENSES/MIT.txt":           true,
+		"licenses/Apache-2.0.txt":    true,
+		"third_party/foo/LICENSE":    true,
+		"PATENTS":                    false,
+		"LICENSES/nested/MIT.txt":    false,
+		"third_party/LICENSE":        false,
+		"docs/LICENSE":               false,
+		"third_party/foo/bar/NOTICE": false,
+	} {
+		assert.Equal(t, want, l.match(rel), rel)
+	}
+
+	assert.True(t, l.descend("LICENSES"))
+	assert.True(t, l.descend("third_party/foo"))
+	assert.False(t, l.descend("docs"))
+	assert.False(t, defaultLicenseFiles(t).descend("third_party"))
+
+	_, err = newLicenseFiles([]string{"LICENSE["})
+	assert.EqualError(t, err, `invalid license file pattern "license[": syntax error in pattern`)
+	_, err = newLicenseFiles([]string{"../LICENSE"})
+	assert.EqualError(t, err, `license file pattern "../license" is outside of the package directory`)
+}
+
+func TestLicenseFileMatcher(t *testing.T) {
+	match, err := LicenseFileMatcher(&Config{LicenseFiles: []string{"third_party/*/LICENSE"}})
+	assert.NoError(t, err)
+
+	for file, want := range map[string]bool{
+		"vendor/github.com/a/b/LICENSE":                      true,
+		"vendor/github.com/a/b/LICENSES/MIT.txt":             true,
+		"vendor/github.com/a/b/third_party/c/LICENSE":        true,
+		"vendor/github.com/a/b/.reuse/dep5":                  false,
+		"vendor/github.com/a/b/b.go":                         false,
+		"vendor/github.com/a/b/LICENSES/nested/MIT.txt":      false,
+		"vendor/github.com/a/b/third_party/c/d/LICENSE.json": false,
+	} {
+		assert.Equal(t, want, match(file), file)
+	}
+
+	_, err = LicenseFileMatcher(&Config{LicenseFiles: []string{"LICENSE["}})
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+}
+
+func TestScanLicenseFilePatterns(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanLicenseFilePatterns")
+	defer rm()
+
+	// the license of the package is only found through the patterns
+	pkg := filepath.Join(dir, "vendor/github.com/fake/package")
+	assert.NoError(t, os.Rename(filepath.Join(pkg, "LICENSE"), filepath.Join(pkg, "LICENSE.BSD")))
+
+	res, err := Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, UnknownLicense, res.Packages[1].License)
+	}
+
+	config := &Config{LicenseFiles: []string{"LICENSE.*"}}
+	res, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "BSD-3-Clause", res.Packages[1].License)
+		assert.Equal(t, "vendor/github.com/fake/package/LICENSE.BSD", res.Packages[1].File)
+	}
+
+	// REUSE projects keep their licenses in a LICENSES directory
+	assert.NoError(t, os.Mkdir(filepath.Join(pkg, "LICENSES"), 0755))
+	assert.NoError(t, os.Rename(filepath.Join(pkg, "LICENSE.BSD"), filepath.Join(pkg, "LICENSES", "BSD-3-Clause.txt")))
+
+	res, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "BSD-3-Clause", res.Packages[1].License)
+		assert.Equal(t, "vendor/github.com/fake/package/LICENSES/BSD-3-Clause.txt", res.Packages[1].File)
+	}
+
+	// every license text is evaluated, the package being under all of them
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "LICENSES", "ISC.txt"), []byte(mockISC), 0644))
+	res, err = Scan(context.Background(), Options{Root: dir, Config: &Config{Allowlist: []string{"BSD-3-Clause"}}, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "BSD-3-Clause AND ISC", res.Packages[1].License)
+		assert.Equal(t, "vendor/github.com/fake/package/LICENSES/BSD-3-Clause.txt", res.Packages[1].File)
+		assert.Equal(t, []string{"vendor/github.com/fake/package/LICENSES/BSD-3-Clause.txt", "vendor/github.com/fake/package/LICENSES/ISC.txt"}, res.Packages[1].Files)
+		assert.Equal(t, DecisionDenied, res.Packages[1].Decision)
+	}
+	res, err = Scan(context.Background(), Options{Root: dir, Config: &Config{Allowlist: []string{"BSD-3-Clause", "ISC"}}, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, DecisionApproved, res.Packages[1].Decision)
+	}
+	assert.NoError(t, os.Remove(filepath.Join(pkg, "LICENSES", "ISC.txt")))
+
+	// LICENSE-<name> files are the dual licensing convention, the license is one of them
+	dual := filepath.Join(dir, "vendor/github.com/fake/nested")
+	assert.NoError(t, os.Rename(filepath.Join(dual, "LICENSE"), filepath.Join(dual, "LICENSE-BSD")))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dual, "LICENSE-ISC"), []byte(mockISC), 0644))
+	res, err = Scan(context.Background(), Options{Root: dir, Config: &Config{Allowlist: []string{"ISC"}, LicenseFiles: []string{"LICENSE-*"}}, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "BSD-3-Clause OR ISC", res.Packages[0].License)
+		assert.Equal(t, []string{"vendor/github.com/fake/nested/LICENSE-BSD", "vendor/github.com/fake/nested/LICENSE-ISC"}, res.Packages[0].Files)
+		assert.Equal(t, DecisionApproved, res.Packages[0].Decision)
+	}
+
+	config.LicenseFiles = []string{"LICENSE["}
+	_, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+
+	// changes to the default patterns apply to the next scan
+	initial := LicenseFilePatterns
+	defer func() { LicenseFilePatterns = initial }()
+	LicenseFilePatterns = nil
+
+	res, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, UnknownLicense, res.Packages[1].License)
+	}
+}
+
+func TestLicenseFilesFind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestLicenseFilesFind")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, f := range []string{"LICENSE", "main.go", "LICENSES/MIT.txt", "LICENSES/CC0-1.0.txt", "docs/LICENSE"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, f), nil, 0644))
+	}
+
+	files, err := defaultLicenseFiles(t).find(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"LICENSE", "LICENSES/CC0-1.0.txt", "LICENSES/MIT.txt"}, files)
+}
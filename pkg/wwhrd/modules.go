@@ -0,0 +1,162 @@
+package wwhrd
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Module describes a module listed in vendor/modules.txt
+type Module struct {
+	Path     string
+	Version  string
+	Explicit bool
+}
+
+// VendorModules maps vendored packages to the module providing them
+type VendorModules struct {
+	Modules  []*Module
+	Packages map[string]*Module
+}
+
+// ReadVendorModules parses vendor/modules.txt, a missing file yields an empty set
+func ReadVendorModules(root string) (*VendorModules, error) {
+	if !strings.HasSuffix(root, "vendor") {
+		root = filepath.Join(root, "vendor")
+	}
+
+	f, err := os.Open(filepath.Join(root, "modules.txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &VendorModules{Packages: make(map[string]*Module)}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseVendorModules(f)
+}
+
+// ParseVendorModules parses the content of a vendor/modules.txt file
+func ParseVendorModules(r io.Reader) (*VendorModules, error) {
+	vm := &VendorModules{Packages: make(map[string]*Module)}
+
+	var current *Module
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if current != nil && strings.HasPrefix(line, "## explicit") {
+				current.Explicit = true
+			}
+		case strings.HasPrefix(line, "# "):
+			// # path version [=> replacement [version]]
+			fields := strings.Fields(strings.TrimPrefix(line, "# "))
+			current = &Module{Path: fields[0]}
+			if len(fields) > 1 && fields[1] != "=>" {
+				current.Version = fields[1]
+			}
+			if i := indexOf(fields, "=>"); i >= 0 && len(fields) > i+2 {
+				current.Version = fields[i+2]
+			}
+			vm.Modules = append(vm.Modules, current)
+		case line != "" && current != nil:
+			vm.Packages[line] = current
+		}
+	}
+
+	return vm, scanner.Err()
+}
+
+// Lookup returns the module providing pkg, falling back to the longest module path prefix
+func (vm *VendorModules) Lookup(pkg string) *Module {
+	if m, ok := vm.Packages[pkg]; ok {
+		return m
+	}
+
+	var found *Module
+	for _, m := range vm.Modules {
+		if pkg == m.Path || strings.HasPrefix(pkg, m.Path+"/") {
+			if found == nil || len(m.Path) > len(found.Path) {
+				found = m
+			}
+		}
+	}
+	return found
+}
+
+func indexOf(s []string, v string) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// Requirement is a require directive from go.mod
+type Requirement struct {
+	Path     string
+	Version  string
+	Line     int
+	Indirect bool
+}
+
+// ReadGoModRequires returns the require directives of the go.mod file in root, keyed by module path,
+// a missing file yields an empty set
+func ReadGoModRequires(root string) (map[string]*Requirement, error) {
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]*Requirement), nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseGoModRequires(f)
+}
+
+// ParseGoModRequires returns the require directives of the content of a go.mod file, keyed by module path
+func ParseGoModRequires(r io.Reader) (map[string]*Requirement, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// the lax parser ignores the directives that don't matter to requirements, as newer ones
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := make(map[string]*Requirement)
+	for _, req := range f.Require {
+		reqs[req.Mod.Path] = &Requirement{
+			Path:     req.Mod.Path,
+			Version:  req.Mod.Version,
+			Line:     req.Syntax.Start.Line,
+			Indirect: req.Indirect,
+		}
+	}
+	return reqs, nil
+}
+
+// ReadModulePath returns the module path declared in the go.mod file in root, or an empty string
+// when there is no go.mod file
+func ReadModulePath(root string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return modfile.ModulePath(data), nil
+}
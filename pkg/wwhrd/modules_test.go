@@ -0,0 +1,82 @@
+package wwhrd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mockModulesTxt = `# github.com/fake/package v1.2.3
+## explicit; go 1.17
+github.com/fake/package
+# github.com/fake/nested v0.1.0 => github.com/fork/nested v0.1.1
+github.com/fake/nested/inside/a/package
+`
+
+func TestReadVendorModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestReadVendorModules")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	vm, err := ReadVendorModules(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, vm.Lookup("github.com/fake/package"))
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), []byte(mockModulesTxt), 0666))
+
+	vm, err = ReadVendorModules(dir)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &Module{Path: "github.com/fake/package", Version: "v1.2.3", Explicit: true}, vm.Lookup("github.com/fake/package"))
+	assert.Equal(t, &Module{Path: "github.com/fake/nested", Version: "v0.1.1"}, vm.Lookup("github.com/fake/nested/inside/a/package"))
+	// unlisted packages fall back to the module path
+	assert.Equal(t, "github.com/fake/nested", vm.Lookup("github.com/fake/nested/other").Path)
+	assert.Nil(t, vm.Lookup("github.com/fake/packages"))
+}
+
+var mockGoMod = `module github.com/fake/root
+
+go 1.22
+
+require github.com/single/line v0.0.1
+
+require (
+	github.com/fake/package v1.2.3
+	github.com/fake/nested v0.1.0 // indirect
+)
+`
+
+func TestReadGoModRequires(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestReadGoModRequires")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	reqs, err := ReadGoModRequires(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, reqs)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(mockGoMod), 0666))
+
+	reqs, err = ReadGoModRequires(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*Requirement{
+		"github.com/single/line":  {Path: "github.com/single/line", Version: "v0.0.1", Line: 5},
+		"github.com/fake/package": {Path: "github.com/fake/package", Version: "v1.2.3", Line: 8},
+		"github.com/fake/nested":  {Path: "github.com/fake/nested", Version: "v0.1.0", Line: 9, Indirect: true},
+	}, reqs)
+
+	// quoted paths and directives unknown to the parser are understood
+	reqs, err = ParseGoModRequires(strings.NewReader("module x\n\nfuture directive\n\nrequire \"github.com/a/b\" v1.0.0 // indirect; for tests\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*Requirement{
+		"github.com/a/b": {Path: "github.com/a/b", Version: "v1.0.0", Line: 5, Indirect: true},
+	}, reqs)
+
+	_, err = ParseGoModRequires(strings.NewReader("require (\n\tgithub.com/a/b\n)\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package wwhrd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Overridden is the name of the detector applying the overrides of the config
+const Overridden = "override"
+
+// Override declares the license of packages, recording a human decision in place of detection
+type Override struct {
+	// License is the identifier of the declared license, or an SPDX license expression
+	License string `yaml:"license"`
+	// Reason explains the decision, it is mandatory
+	Reason string `yaml:"reason"`
+	// SHA256 is the checksum of the license file the decision was made against, when set the
+	// override only applies as long as the file is unchanged
+	SHA256 string `yaml:"sha256"`
+	// File is the license file, relative to the vendor directory, it defaults to the license
+	// files of the package or of its nearest parent, it is mandatory along with SHA256 when there
+	// are several of them
+	File string `yaml:"file"`
+}
+
+// overrideDetector applies overrides before any other detector, most specific pattern first
+type overrideDetector struct {
+	vendor    string
+	files     *licenseFiles
+	overrides map[string]Override
+	// patterns are sorted longest first
+	patterns []string
+}
+
+// newOverrideDetector validates overrides, keyed by package or, ending in /..., by package prefix
+func newOverrideDetector(vendor string, files *licenseFiles, overrides map[string]Override) (*overrideDetector, error) {
+	d := &overrideDetector{vendor: vendor, files: files, overrides: overrides}
+	for pattern, o := range overrides {
+		switch {
+		case o.License == "":
+			return nil, fmt.Errorf("override %s: license is mandatory", pattern)
+		case strings.TrimSpace(o.Reason) == "":
+			return nil, fmt.Errorf("override %s: reason is mandatory", pattern)
+		case o.SHA256 != "":
+			if b, err := hex.DecodeString(o.SHA256); err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("override %s: invalid sha256 %q", pattern, o.SHA256)
+			}
+		}
+		if _, err := parseLicenseExpression(o.License); err != nil {
+			return nil, fmt.Errorf("override %s: %s", pattern, err)
+		}
+		d.patterns = append(d.patterns, pattern)
+	}
+	sort.Slice(d.patterns, func(i, j int) bool {
+		if len(d.patterns[i]) != len(d.patterns[j]) {
+			return len(d.patterns[i]) > len(d.patterns[j])
+		}
+		return d.patterns[i] < d.patterns[j]
+	})
+	return d, nil
+}
+
+// Name implements Detector
+func (d *overrideDetector) Name() string {
+	return Overridden
+}
+
+// Detect implements Detector, stale overrides are ignored with a warning so that the license
+// gets detected again
+func (d *overrideDetector) Detect(pkg Package) (*Detection, error) {
+	pattern, ok := d.match(pkg.Path)
+	if !ok {
+		return nil, nil
+	}
+	o := d.overrides[pattern]
+
+	// the license texts are kept for the notices
+	var files []string
+	if o.File != "" {
+		files = []string{filepath.Join(d.vendor, filepath.FromSlash(o.File))}
+	} else {
+		var err error
+		if files, err = d.nearestLicenseFiles(pkg.Dir); err != nil {
+			return nil, err
+		}
+		if o.SHA256 != "" && len(files) > 1 {
+			return nil, fmt.Errorf("override %s: %s has several license files, the one the checksum is for must be set in file", pattern, filepath.Dir(files[0]))
+		}
+	}
+	var file string
+	if len(files) > 0 {
+		file = files[0]
+	}
+
+	if o.SHA256 != "" {
+		text, err := ioutil.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		sum := sha256.Sum256(text)
+		if err != nil || !strings.EqualFold(hex.EncodeToString(sum[:]), o.SHA256) {
+			log.WithFields(log.Fields{
+				"package":  pkg.Path,
+				"override": pattern,
+			}).Warn("Ignoring override, the license file changed")
+			return nil, nil
+		}
+	}
+
+	return &Detection{License: o.License, File: file, Files: files, Confidence: 100, Detector: Overridden, Reason: o.Reason}, nil
+}
+
+// match returns the most specific pattern matching pkg
+func (d *overrideDetector) match(pkg string) (string, bool) {
+	for _, p := range d.patterns {
+		if p == pkg {
+			return p, true
+		}
+		if prefix := strings.TrimSuffix(p, "/..."); prefix != p && (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// nearestLicenseFiles returns the license files found in dir or, when there are none, in its
+// nearest parent up to the vendor directory having some
+func (d *overrideDetector) nearestLicenseFiles(dir string) ([]string, error) {
+	for dir != d.vendor && strings.HasPrefix(dir, d.vendor) {
+		files, err := d.files.find(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(files) > 0 {
+			for i, f := range files {
+				files[i] = filepath.Join(dir, filepath.FromSlash(f))
+			}
+			return files, nil
+		}
+		dir = filepath.Dir(dir)
+	}
+	return nil, nil
+}
@@ -0,0 +1,120 @@
+package wwhrd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOverrideDetector(t *testing.T) {
+	_, err := newOverrideDetector("vendor", defaultLicenseFiles(t), map[string]Override{"a": {License: "MIT"}})
+	assert.EqualError(t, err, "override a: reason is mandatory")
+
+	_, err = newOverrideDetector("vendor", defaultLicenseFiles(t), map[string]Override{"a": {Reason: "reviewed"}})
+	assert.EqualError(t, err, "override a: license is mandatory")
+
+	_, err = newOverrideDetector("vendor", defaultLicenseFiles(t), map[string]Override{"a": {License: "MIT", Reason: "reviewed", SHA256: "abc"}})
+	assert.EqualError(t, err, `override a: invalid sha256 "abc"`)
+
+	_, err = newOverrideDetector("vendor", defaultLicenseFiles(t), map[string]Override{"a": {License: "MIT OR", Reason: "reviewed"}})
+	assert.EqualError(t, err, `override a: invalid license expression "MIT OR": unexpected end`)
+
+	d, err := newOverrideDetector("vendor", defaultLicenseFiles(t), map[string]Override{
+		"github.com/fake/...":        {License: "MIT", Reason: "reviewed"},
+		"github.com/fake/nested/...": {License: "ISC", Reason: "reviewed"},
+		"github.com/fake/package":    {License: "Apache-2.0", Reason: "reviewed"},
+	})
+	assert.NoError(t, err)
+
+	for pkg, want := range map[string]string{
+		"github.com/fake":                      "github.com/fake/...",
+		"github.com/fake/package":              "github.com/fake/package",
+		"github.com/fake/package/sub":          "github.com/fake/...",
+		"github.com/fake/nested/inside/a/pack": "github.com/fake/nested/...",
+		"github.com/fakes/package":             "",
+	} {
+		got, _ := d.match(pkg)
+		assert.Equal(t, want, got, pkg)
+	}
+}
+
+func TestScanOverrides(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanOverrides")
+	defer rm()
+
+	sum := sha256.Sum256([]byte(mockLicense))
+	config := &Config{
+		Allowlist: []string{"MIT"},
+		Overrides: map[string]Override{
+			"github.com/fake/nested/...": {License: "MIT", Reason: "relicensed", SHA256: hex.EncodeToString(sum[:])},
+			"github.com/fake/package":    {License: "MIT", Reason: "relicensed", SHA256: hex.EncodeToString(make([]byte, sha256.Size))},
+		},
+	}
+	res, err := Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.NoError(t, err)
+
+	if assert.Len(t, res.Packages, 2) {
+		nested, fake := res.Packages[0], res.Packages[1]
+		assert.Equal(t, "MIT", nested.License)
+		assert.Equal(t, Overridden, nested.Detector)
+		assert.Equal(t, "relicensed", nested.Reason)
+		assert.Equal(t, "vendor/github.com/fake/nested/LICENSE", nested.File)
+		assert.Equal(t, DecisionApproved, nested.Decision)
+
+		// the checksum doesn't match, the license is detected again
+		assert.Equal(t, "BSD-3-Clause", fake.License)
+		assert.Equal(t, LicenseFiles, fake.Detector)
+		assert.Empty(t, fake.Reason)
+		assert.Equal(t, DecisionDenied, fake.Decision)
+	}
+
+	// overrides without checksum can point to any file
+	config.Overrides = map[string]Override{
+		"github.com/fake/package": {License: "MIT", Reason: "see README", File: "github.com/fake/package/mockpkg.go"},
+	}
+	res, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "MIT", res.Packages[1].License)
+		assert.Equal(t, "vendor/github.com/fake/package/mockpkg.go", res.Packages[1].File)
+	}
+
+	// without checksum nor file, the license texts are kept for the notices
+	config.Overrides["github.com/fake/package"] = Override{License: "MIT", Reason: "relicensed"}
+	res, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "vendor/github.com/fake/package/LICENSE", res.Packages[1].File)
+		assert.Equal(t, []string{"vendor/github.com/fake/package/LICENSE"}, res.Packages[1].Files)
+	}
+
+	// with several license files, the checksum must say which one it is for
+	pkg := filepath.Join(dir, "vendor/github.com/fake/package")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "COPYING"), []byte(mockLicense), 0644))
+	res, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, []string{"vendor/github.com/fake/package/COPYING", "vendor/github.com/fake/package/LICENSE"}, res.Packages[1].Files)
+	}
+	config.Overrides["github.com/fake/package"] = Override{License: "MIT", Reason: "relicensed", SHA256: hex.EncodeToString(sum[:])}
+	_, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "override github.com/fake/package: "+pkg+" has several license files, the one the checksum is for must be set in file")
+	}
+	config.Overrides["github.com/fake/package"] = Override{License: "MIT", Reason: "relicensed", SHA256: hex.EncodeToString(sum[:]), File: "github.com/fake/package/LICENSE"}
+	res, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, Overridden, res.Packages[1].Detector)
+	}
+
+	config.Overrides["github.com/fake/package"] = Override{License: "MIT"}
+	_, err = Scan(context.Background(), Options{Root: dir, Config: config, CoverageThreshold: 75})
+	assert.True(t, errors.Is(err, ErrConfigInvalid))
+}
@@ -0,0 +1,214 @@
+package wwhrd
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Decision is the outcome of evaluating a package license against the config
+type Decision string
+
+const (
+	DecisionApproved    Decision = "approved"
+	DecisionExceptioned Decision = "exceptioned"
+	DecisionDenied      Decision = "denied"
+	// DecisionBaselined is a denied package that was already denied in the baseline
+	DecisionBaselined Decision = "baselined"
+)
+
+// PackageResult is the evaluation of a single package against the config
+type PackageResult struct {
+	Package string
+	Module  string
+	Version string
+	// Required is set when go.mod requires the module, Direct when it does without an
+	// indirect comment
+	Required bool
+	Direct   bool
+	License  string
+	File     string
+	// Files are all the license texts the license was derived from, File included
+	Files []string
+	// Source is the file declaring the license when it isn't a license text
+	Source        string
+	Coverage      float64
+	Detector      string
+	Confidence    float64
+	Decision      Decision
+	Exception     string
+	Justification string
+	// Reason is the reason of the override declaring the license, if any
+	Reason string
+}
+
+// policy holds the config lists in a form suitable for fast lookups
+type policy struct {
+	allowlist          map[string]bool
+	denylist           map[string]bool
+	exceptions         map[string]bool
+	exceptionsWildcard map[string]bool
+	justifications     map[string]string
+}
+
+func newPolicy(c *Config) *policy {
+	p := &policy{
+		allowlist:          make(map[string]bool),
+		denylist:           make(map[string]bool),
+		exceptions:         make(map[string]bool),
+		exceptionsWildcard: make(map[string]bool),
+		justifications:     c.Justifications,
+	}
+
+	// Make a map out of the denylist
+	for _, v := range c.Denylist {
+		p.denylist[v] = true
+	}
+
+	// Make a map out of the allowlist
+	for _, v := range c.Allowlist {
+		p.allowlist[v] = true
+	}
+
+	// Make a map out of the exceptions list
+	for _, v := range c.Exceptions {
+		if strings.HasSuffix(v, "/...") {
+			p.exceptionsWildcard[strings.TrimSuffix(v, "/...")] = true
+		} else {
+			p.exceptions[v] = true
+		}
+	}
+
+	return p
+}
+
+// approved tells if lic is allowlisted and not denylisted. SPDX license expressions, such as the
+// licenses of packages with several license files, are approved when all the operands of AND and
+// one of the operands of OR are, unless listed as a whole. Invalid expressions are never approved.
+func (p *policy) approved(lic string) bool {
+	switch {
+	case p.denylist[lic]:
+		return false
+	case p.allowlist[lic]:
+		return true
+	case len(tokenizeExpression(lic)) == 1:
+		return false
+	}
+
+	e, err := parseLicenseExpression(lic)
+	if err != nil {
+		log.Debugf("License %q is not approved: %s", lic, err)
+		return false
+	}
+	return e.approved(func(id string) bool {
+		return p.allowlist[id] && !p.denylist[id]
+	})
+}
+
+// evaluate returns the decision for pkg and, when exceptioned, the matching exception entry
+func (p *policy) evaluate(pkg, lic string) (Decision, string) {
+	// License is allowlisted and not specified in denylist
+	if p.approved(lic) {
+		return DecisionApproved, ""
+	}
+
+	// if we have exceptions wildcards, let's run through them, the most specific one wins
+	var match string
+	for wc := range p.exceptionsWildcard {
+		if (pkg == wc || strings.HasPrefix(pkg, wc+"/")) && len(wc) > len(match) {
+			match = wc
+		}
+	}
+	if match != "" {
+		return DecisionExceptioned, match + "/..."
+	}
+
+	// match single-package exceptions
+	if p.exceptions[pkg] {
+		return DecisionExceptioned, pkg
+	}
+
+	// no matches, it's a non-approved license
+	return DecisionDenied, ""
+}
+
+// evaluate detects the licenses of the packages in list and evaluates them against the config,
+// results are sorted by package. A nil config leaves the decision empty. Unreadable paths are
+// recorded in errs.
+func evaluate(opts Options, list map[string]bool, errs *scanErrors) ([]PackageResult, error) {
+	mods, err := ReadVendorModules(opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs, err := ReadGoModRequires(opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *policy
+	if opts.Config != nil {
+		p = newPolicy(opts.Config)
+	}
+
+	lics, err := detectLicenses(opts, list, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []PackageResult
+	for pkg, lic := range lics {
+		r := PackageResult{
+			Package:    pkg,
+			License:    lic.License,
+			File:       lic.File,
+			Detector:   lic.Detector,
+			Confidence: lic.Confidence,
+			Reason:     lic.Reason,
+		}
+		if lic.Detector == LicenseFiles {
+			r.Coverage = lic.Confidence
+		}
+		for _, d := range lic.Detections {
+			if !d.Winner && d.License != lic.License {
+				log.Debugf("%s detector found %s for %s, %s detector won with %s", d.Detector, d.License, pkg, lic.Detector, lic.License)
+			}
+		}
+		if rel, err := filepath.Rel(opts.Root, lic.File); err == nil && lic.File != "" {
+			r.File = filepath.ToSlash(rel)
+		}
+		if rel, err := filepath.Rel(opts.Root, lic.Source); err == nil && lic.Source != "" {
+			r.Source = filepath.ToSlash(rel)
+		}
+		files := lic.Files
+		if len(files) == 0 && lic.File != "" {
+			files = []string{lic.File}
+		}
+		for _, f := range files {
+			if rel, err := filepath.Rel(opts.Root, f); err == nil {
+				r.Files = append(r.Files, filepath.ToSlash(rel))
+			}
+		}
+		if m := mods.Lookup(pkg); m != nil {
+			r.Module = m.Path
+			r.Version = m.Version
+			if req, ok := reqs[m.Path]; ok {
+				r.Required = true
+				r.Direct = !req.Indirect
+			}
+		}
+		if p != nil {
+			r.Decision, r.Exception = p.evaluate(pkg, lic.License)
+			r.Justification = p.justifications[r.Exception]
+		}
+		results = append(results, r)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Package < results[j].Package
+	})
+
+	return results, nil
+}
@@ -0,0 +1,47 @@
+package wwhrd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	p := newPolicy(&Config{
+		Allowlist:  []string{"MIT", "ISC", "Apache-2.0", "MIT OR GPL-3.0"},
+		Denylist:   []string{"Apache-2.0"},
+		Exceptions: []string{"github.com/foo/...", "github.com/foo/bar/...", "github.com/baz/qux"},
+	})
+
+	cases := []struct {
+		pkg, license string
+		decision     Decision
+		exception    string
+	}{
+		{"github.com/any/pkg", "MIT", DecisionApproved, ""},
+		{"github.com/any/pkg", "GPL-2.0", DecisionDenied, ""},
+		{"github.com/foo", "GPL-2.0", DecisionExceptioned, "github.com/foo/..."},
+		{"github.com/foo/sub", "GPL-2.0", DecisionExceptioned, "github.com/foo/..."},
+		{"github.com/foo/bar/sub", "GPL-2.0", DecisionExceptioned, "github.com/foo/bar/..."},
+		// wildcards only match whole path elements
+		{"github.com/foobar", "GPL-2.0", DecisionDenied, ""},
+		{"github.com/baz/qux", "GPL-2.0", DecisionExceptioned, "github.com/baz/qux"},
+		{"github.com/baz/qux/sub", "GPL-2.0", DecisionDenied, ""},
+		// expressions need all the operands of AND and one of the operands of OR to be approved
+		{"github.com/any/pkg", "ISC AND MIT", DecisionApproved, ""},
+		{"github.com/any/pkg", "GPL-2.0 AND MIT", DecisionDenied, ""},
+		{"github.com/any/pkg", "Apache-2.0 AND MIT", DecisionDenied, ""},
+		{"github.com/any/pkg", "GPL-2.0 OR (ISC AND MIT)", DecisionApproved, ""},
+		{"github.com/any/pkg", "Apache-2.0 or GPL-2.0", DecisionDenied, ""},
+		{"github.com/any/pkg", "MIT OR GPL-3.0", DecisionApproved, ""},
+		{"github.com/any/pkg", "MIT WITH Some-exception", DecisionDenied, ""},
+		{"github.com/any/pkg", "(MIT OR", DecisionDenied, ""},
+		{"github.com/foo/sub", "GPL-2.0 AND MIT", DecisionExceptioned, "github.com/foo/..."},
+	}
+
+	for _, c := range cases {
+		decision, exception := p.evaluate(c.pkg, c.license)
+		assert.Equal(t, c.decision, decision, c.pkg)
+		assert.Equal(t, c.exception, exception, c.pkg)
+	}
+}
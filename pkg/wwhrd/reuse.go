@@ -0,0 +1,237 @@
+package wwhrd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// REUSE is the name of the detector reading the licenses declared in the .reuse/dep5 file of a
+// project, following the REUSE specification, see https://reuse.software
+const REUSE = "reuse"
+
+// dep5Stanza is a Files paragraph of a dep5 file
+type dep5Stanza struct {
+	files   []*regexp.Regexp
+	license string
+}
+
+// dep5File is the parsed dep5 file of a directory, done is closed once it is known
+type dep5File struct {
+	done    chan struct{}
+	stanzas []dep5Stanza
+	found   bool
+	err     error
+}
+
+// reuseDetector declares the license of packages covered by a .reuse/dep5 file, in the package
+// directory or in its parents up to the vendor directory. Each dep5 file is only read once.
+type reuseDetector struct {
+	vendor string
+
+	mu   sync.Mutex
+	dirs map[string]*dep5File
+}
+
+func newReuseDetector(vendor string) *reuseDetector {
+	return &reuseDetector{vendor: vendor, dirs: make(map[string]*dep5File)}
+}
+
+// Name implements Detector
+func (d *reuseDetector) Name() string {
+	return REUSE
+}
+
+// Detect implements Detector. Each go file of the package is under the license of the last
+// paragraph matching it, as in dep5 files, and the package under all of them. The license texts
+// are the files of the LICENSES directory named after the licenses, the dep5 file is the source.
+func (d *reuseDetector) Detect(pkg Package) (*Detection, error) {
+	for dir := pkg.Dir; dir != d.vendor && strings.HasPrefix(dir, d.vendor); dir = filepath.Dir(dir) {
+		f := d.load(dir)
+		if f.err != nil {
+			return nil, f.err
+		}
+		if !f.found {
+			continue
+		}
+
+		rel, err := filepath.Rel(dir, pkg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		files, err := ioutil.ReadDir(pkg.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		var licenses []string
+		for _, file := range files {
+			name := file.Name()
+			if file.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			var license string
+			for _, s := range f.stanzas {
+				if s.match(path.Join(filepath.ToSlash(rel), name)) {
+					license = s.license
+				}
+			}
+			if license != "" {
+				licenses = append(licenses, license)
+			}
+		}
+		if len(licenses) == 0 {
+			return nil, nil
+		}
+
+		det := &Detection{License: joinLicenses(licenses), Confidence: 100, Source: filepath.Join(dir, ".reuse", "dep5")}
+		if det.Files, err = licenseTexts(dir, det.License); err != nil {
+			return nil, err
+		}
+		if len(det.Files) > 0 {
+			det.File = det.Files[0]
+		}
+		return det, nil
+	}
+	return nil, nil
+}
+
+// licenseTexts returns the texts of the licenses of the expression found in the LICENSES directory
+// of a REUSE project, named after the license ID with any extension
+func licenseTexts(dir, license string) ([]string, error) {
+	e, err := parseLicenseExpression(license)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(filepath.Join(dir, "LICENSES"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var texts []string
+	seen := make(map[string]bool)
+	for _, id := range e.licenses() {
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && strings.TrimSuffix(name, filepath.Ext(name)) == id && !seen[name] {
+				seen[name] = true
+				texts = append(texts, filepath.Join(dir, "LICENSES", name))
+			}
+		}
+	}
+	return texts, nil
+}
+
+// load returns the dep5 file of dir
+func (d *reuseDetector) load(dir string) *dep5File {
+	d.mu.Lock()
+	f, ok := d.dirs[dir]
+	if !ok {
+		f = &dep5File{done: make(chan struct{})}
+		d.dirs[dir] = f
+	}
+	d.mu.Unlock()
+
+	if ok {
+		<-f.done
+		return f
+	}
+
+	r, err := os.Open(filepath.Join(dir, ".reuse", "dep5"))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		f.err = err
+	default:
+		f.found = true
+		if f.stanzas, err = parseDep5(r); err != nil {
+			f.err = fmt.Errorf("can't parse %s: %s", r.Name(), err)
+		}
+		r.Close()
+	}
+	close(f.done)
+	return f
+}
+
+func (s dep5Stanza) match(file string) bool {
+	for _, re := range s.files {
+		if re.MatchString(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDep5 reads the Files paragraphs of a machine-readable debian/copyright file, patterns are
+// relative to the project directory, * matching any sequence of characters including / and ?
+// matching any single character
+func parseDep5(r io.Reader) ([]dep5Stanza, error) {
+	var stanzas []dep5Stanza
+	fields := map[string]string{}
+	var last string
+
+	flush := func() error {
+		defer func() { fields = map[string]string{} }()
+		files, ok := fields["files"]
+		if !ok {
+			return nil
+		}
+		license := strings.TrimSpace(strings.SplitN(fields["license"], "\n", 2)[0])
+		if license == "" {
+			return fmt.Errorf("files %q have no license", strings.TrimSpace(files))
+		}
+		if _, err := parseLicenseExpression(license); err != nil {
+			return fmt.Errorf("files %q: %s", strings.TrimSpace(files), err)
+		}
+		s := dep5Stanza{license: license}
+		for _, p := range strings.Fields(files) {
+			re := regexp.QuoteMeta(strings.TrimPrefix(p, "./"))
+			re = strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(re)
+			s.files = append(s.files, regexp.MustCompile("^"+re+"$"))
+		}
+		stanzas = append(stanzas, s)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			last = ""
+		case strings.HasPrefix(line, "#"):
+		case line[0] == ' ' || line[0] == '\t':
+			if last == "" {
+				return nil, fmt.Errorf("line %d: continuation without a field", n)
+			}
+			fields[last] += "\n" + strings.TrimSpace(line)
+		default:
+			i := strings.Index(line, ":")
+			if i < 0 {
+				return nil, fmt.Errorf("line %d: missing field name", n)
+			}
+			last = strings.ToLower(strings.TrimSpace(line[:i]))
+			fields[last] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return stanzas, nil
+}
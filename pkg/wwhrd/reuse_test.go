@@ -0,0 +1,101 @@
+package wwhrd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mockDep5 = `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: nested
+Source: https://github.com/fake/nested
+
+# everything is Apache-2.0, unless stated otherwise
+Files: *
+Copyright: 2020 Fake
+License: Apache-2.0
+
+Files: inside/a/*.go
+  docs/*
+Copyright: 2021 Fake
+License: MIT
+ The MIT license text may follow.
+`
+
+func TestParseDep5(t *testing.T) {
+	stanzas, err := parseDep5(strings.NewReader(mockDep5))
+	assert.NoError(t, err)
+
+	if assert.Len(t, stanzas, 2) {
+		assert.Equal(t, "Apache-2.0", stanzas[0].license)
+		assert.True(t, stanzas[0].match("any/file.go"))
+		assert.Equal(t, "MIT", stanzas[1].license)
+		assert.True(t, stanzas[1].match("inside/a/package/mockpkg.go"))
+		assert.True(t, stanzas[1].match("docs/index.md"))
+		assert.False(t, stanzas[1].match("inside/b/mockpkg.go"))
+	}
+
+	_, err = parseDep5(strings.NewReader("Files: *\nCopyright: none\n"))
+	assert.EqualError(t, err, `files "*" have no license`)
+
+	_, err = parseDep5(strings.NewReader("Files: *\nnot a field\n"))
+	assert.EqualError(t, err, "line 2: missing field name")
+
+	_, err = parseDep5(strings.NewReader("Files: *\nLicense: MIT OR\n"))
+	assert.EqualError(t, err, `files "*": invalid license expression "MIT OR": unexpected end`)
+}
+
+func TestScanReuse(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanReuse")
+	defer rm()
+
+	nested := filepath.Join(dir, "vendor/github.com/fake/nested")
+	assert.NoError(t, os.Mkdir(filepath.Join(nested, ".reuse"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(nested, ".reuse", "dep5"), []byte(mockDep5), 0644))
+
+	res, err := Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75})
+	assert.NoError(t, err)
+
+	if assert.Len(t, res.Packages, 2) {
+		// dep5 files take precedence over the license texts
+		assert.Equal(t, "MIT", res.Packages[0].License)
+		assert.Equal(t, REUSE, res.Packages[0].Detector)
+		assert.Equal(t, "vendor/github.com/fake/nested/.reuse/dep5", res.Packages[0].Source)
+		// the license text is missing
+		assert.Empty(t, res.Packages[0].File)
+		assert.Equal(t, "BSD-3-Clause", res.Packages[1].License)
+		assert.Equal(t, LicenseFiles, res.Packages[1].Detector)
+	}
+
+	// every go file of the package counts, the other files don't
+	pkg := filepath.Join(nested, "inside/a/package")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "gen.go"), []byte("package main\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "README.md"), nil, 0644))
+	dep5 := mockDep5 + "\nFiles: inside/a/package/gen.go\nLicense: ISC\n\nFiles: inside/a/package/README.md\nLicense: GPL-2.0-only\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(nested, ".reuse", "dep5"), []byte(dep5), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(nested, "LICENSES"), 0755))
+	for _, f := range []string{"ISC.txt", "MIT.txt", "GPL-2.0-only.txt"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(nested, "LICENSES", f), nil, 0644))
+	}
+
+	res, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 2) {
+		assert.Equal(t, "ISC AND MIT", res.Packages[0].License)
+		// the license texts are the files of the LICENSES directory
+		assert.Equal(t, "vendor/github.com/fake/nested/LICENSES/ISC.txt", res.Packages[0].File)
+		assert.Equal(t, []string{"vendor/github.com/fake/nested/LICENSES/ISC.txt", "vendor/github.com/fake/nested/LICENSES/MIT.txt"}, res.Packages[0].Files)
+		assert.Equal(t, "vendor/github.com/fake/nested/.reuse/dep5", res.Packages[0].Source)
+	}
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(nested, ".reuse", "dep5"), []byte("Files *\n"), 0644))
+	_, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "reuse detector: can't parse")
+	}
+}
@@ -0,0 +1,98 @@
+package wwhrd
+
+import (
+	"context"
+	"runtime"
+)
+
+// Options configures a walk or a scan of a project
+type Options struct {
+	// Root is the directory of the project, holding its vendor directory
+	Root string
+	// Config is the policy licenses are evaluated against, decisions are left empty when nil
+	Config *Config
+	// CoverageThreshold is the minimum percentage of a file that must match a license
+	CoverageThreshold float64
+	// CheckTestFiles also walks the imports of test files
+	CheckTestFiles bool
+	// Jobs is the number of concurrent workers, the number of CPUs when 0
+	Jobs int
+	// KeepGoing carries on past unreadable paths, which are then reported by an IncompleteError
+	KeepGoing bool
+	// CacheDir is the directory of the license cache, the cache is disabled when empty
+	CacheDir string
+	// Include selects the packages whose license is scanned, all of them when nil
+	Include func(pkg string) bool
+	// Detectors are asked in order for the license of each package, before the license files
+	Detectors []Detector
+}
+
+func (o Options) jobs() int {
+	if o.Jobs > 0 {
+		return o.Jobs
+	}
+	return runtime.NumCPU()
+}
+
+// Result is the outcome of a scan
+type Result struct {
+	// Graph is the import graph of the project
+	Graph *Graph
+	// Packages are the scanned packages that have a license file, sorted by package
+	Packages []PackageResult
+}
+
+// Denied returns the packages whose license is not approved by the config
+func (r *Result) Denied() []PackageResult {
+	var denied []PackageResult
+	for _, p := range r.Packages {
+		if p.Decision == DecisionDenied {
+			denied = append(denied, p)
+		}
+	}
+	return denied
+}
+
+// Check returns ErrNonApprovedLicense when a package license is not approved by the config
+func (r *Result) Check() error {
+	if len(r.Denied()) > 0 {
+		return ErrNonApprovedLicense
+	}
+	return nil
+}
+
+// Walk walks the imports of the project in opts.Root and returns its import graph. When keeping
+// going past unreadable paths, the graph is returned along with an IncompleteError listing them.
+func Walk(ctx context.Context, opts Options) (*Graph, error) {
+	errs := newScanErrors(opts.KeepGoing)
+	graph, err := walkGraph(ctx, opts, errs)
+	if err != nil {
+		return nil, err
+	}
+	return graph, errs.err()
+}
+
+// Scan walks the imports of the project in opts.Root, detects the license of every imported
+// package and evaluates it against opts.Config. When keeping going past unreadable paths, the
+// result is returned along with an IncompleteError listing them.
+func Scan(ctx context.Context, opts Options) (*Result, error) {
+	errs := newScanErrors(opts.KeepGoing)
+	graph, err := walkGraph(ctx, opts, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(map[string]bool)
+	for _, pkg := range graph.Packages() {
+		if opts.Include == nil || opts.Include(pkg) {
+			list[pkg] = true
+		}
+	}
+
+	pkgs, err := evaluate(opts, list, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Graph: graph, Packages: pkgs}, errs.err()
+}
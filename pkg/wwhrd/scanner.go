@@ -0,0 +1,292 @@
+package wwhrd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/licensecheck"
+	log "github.com/sirupsen/logrus"
+)
+
+// dirScan is the license of a directory, done is closed once it is known
+type dirScan struct {
+	done    chan struct{}
+	license licenseInfo
+}
+
+// textScan is the coverage of a license text, done is closed once it is known
+type textScan struct {
+	done     chan struct{}
+	coverage licensecheck.Coverage
+}
+
+// licenseScanner detects the license of vendored packages, it can be used concurrently.
+// Each directory is scanned once, its license being shared by the packages beneath it, and each
+// distinct license text is only checked once.
+type licenseScanner struct {
+	checker   *licensecheck.Scanner
+	threshold float64
+	cache     *licenseCache
+	files     *licenseFiles
+	errs      *scanErrors
+
+	mu     sync.Mutex
+	dirs   map[string]*dirScan
+	texts  map[string]*textScan
+	failed error
+}
+
+func newLicenseScanner(checker *licensecheck.Scanner, threshold float64, files *licenseFiles, errs *scanErrors) *licenseScanner {
+	return &licenseScanner{
+		checker:   checker,
+		threshold: threshold,
+		files:     files,
+		errs:      errs,
+		dirs:      make(map[string]*dirScan),
+		texts:     make(map[string]*textScan),
+	}
+}
+
+// Name implements Detector
+func (s *licenseScanner) Name() string {
+	return LicenseFiles
+}
+
+// Detect implements Detector, the unreadable paths met are recorded in the scanner errors
+func (s *licenseScanner) Detect(pkg Package) (*Detection, error) {
+	lic := s.scanDir(pkg.Dir)
+	if lic.license == UnknownLicense {
+		return nil, nil
+	}
+	return &Detection{License: lic.license, File: lic.file, Files: lic.files, Confidence: lic.coverage, Detector: LicenseFiles}, nil
+}
+
+// scanPackages returns the license of every package of list found in the vendor directory, as
+// found by detector, using jobs workers. Packages no detector can tell the license of are UNKNOWN.
+// Unless keeping going, scanning stops at the first unreadable path or detector error, otherwise
+// they are recorded in the scanner errors.
+func (s *licenseScanner) scanPackages(vendor string, list map[string]bool, jobs int, detector Detector) (map[string]Detection, error) {
+	type scanned struct {
+		pkg       string
+		detection Detection
+	}
+
+	pkgs := make(chan string)
+	results := make(chan scanned)
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range pkgs {
+				if s.err() != nil {
+					continue
+				}
+				var fpath = filepath.Join(vendor, k)
+				pkg, err := os.Stat(fpath)
+				if err != nil && !os.IsNotExist(err) {
+					s.fail(fmt.Errorf("can't read package: %s", err))
+					continue
+				}
+				if err != nil || !pkg.IsDir() {
+					continue
+				}
+				log.Debugf("Walking path: %s", fpath)
+				det, err := detector.Detect(Package{Path: k, Dir: fpath})
+				if err != nil {
+					s.fail(fmt.Errorf("can't detect license of %s: %w", k, err))
+					continue
+				}
+				if det == nil {
+					lic := s.scanDir(fpath)
+					det = &Detection{License: UnknownLicense, File: lic.file, Files: lic.files}
+				}
+				results <- scanned{pkg: k, detection: *det}
+			}
+		}()
+	}
+
+	go func() {
+		for k := range list {
+			pkgs <- k
+		}
+		close(pkgs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var lics = make(map[string]Detection)
+	for r := range results {
+		lics[r.pkg] = r.detection
+	}
+
+	if err := s.err(); err != nil {
+		return nil, err
+	}
+	return lics, nil
+}
+
+// fail records err, stopping the scan unless keeping going
+func (s *licenseScanner) fail(err error) {
+	if err = s.errs.add(err); err != nil {
+		s.mu.Lock()
+		if s.failed == nil {
+			s.failed = err
+		}
+		s.mu.Unlock()
+	}
+}
+
+// err returns the error the scan stopped at
+func (s *licenseScanner) err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failed
+}
+
+// scanDir returns the license of the directory, falling back on its parents up to the vendor directory
+func (s *licenseScanner) scanDir(fpath string) licenseInfo {
+	s.mu.Lock()
+	d, ok := s.dirs[fpath]
+	if !ok {
+		d = &dirScan{done: make(chan struct{})}
+		s.dirs[fpath] = d
+	}
+	s.mu.Unlock()
+
+	if ok {
+		<-d.done
+		return d.license
+	}
+
+	d.license = s.walkDir(fpath)
+	close(d.done)
+	return d.license
+}
+
+// walkDir evaluates every license file of the directory, the package being under all the licenses
+// of the files meeting the threshold. The LICENSE-<name> files, e.g. LICENSE-APACHE and LICENSE-MIT,
+// are alternatives to choose from. When no file meets the threshold, here or in the parents, the
+// license is UNKNOWN and the files are the license files of the nearest directory having some.
+func (s *licenseScanner) walkDir(fpath string) licenseInfo {
+	var license = licenseInfo{}
+	var licenses []string
+	var candidates []string
+	var alternatives []string
+
+	// only the well-known license files and the ones matching the license file patterns are read
+	filesInDir, err := s.files.find(fpath)
+	if err != nil {
+		s.fail(fmt.Errorf("can't read directory: %s", err))
+		return license
+	}
+	for _, f := range filesInDir {
+		log.Debugf("Evaluating: %s", f)
+
+		// Read the license file
+		text, err := ioutil.ReadFile(filepath.Join(fpath, filepath.FromSlash(f)))
+		if err != nil {
+			s.fail(fmt.Errorf("can't read license file: %s", err))
+			continue
+		}
+
+		// Verify against the checker
+		cov := s.scanText(text)
+		log.Debugf("%.1f%% of text covered by licenses:\n", cov.Percent)
+		for _, m := range cov.Match {
+			log.Debugf("%s at [%d:%d] IsURL=%v\n", m.ID, m.Start, m.End, m.IsURL)
+		}
+
+		// If the threshold is met, we qualify the license
+		if cov.Percent >= s.threshold {
+			file := filepath.Join(fpath, filepath.FromSlash(f))
+			if license.file == "" || cov.Percent < license.coverage {
+				license.coverage = cov.Percent
+			}
+			if license.file == "" {
+				license.file = file
+			}
+			license.files = append(license.files, file)
+			if isAlternativeLicenseFile(f) {
+				alternatives = append(alternatives, cov.Match[0].ID)
+			} else {
+				licenses = append(licenses, cov.Match[0].ID)
+			}
+		} else {
+			candidates = append(candidates, filepath.Join(fpath, filepath.FromSlash(f)))
+		}
+	}
+	if len(alternatives) > 0 {
+		licenses = append(licenses, anyLicense(alternatives))
+	}
+	license.license = joinLicenses(licenses)
+
+	// if we didn't find any licenses after walking the path, we pop one out from it
+	if license.license == "" {
+		pak := strings.Split(filepath.ToSlash(fpath), "/")
+		// if we're 1 directories removed from vendor/ that means we couldn't find a decent license file
+		if pak[len(pak)-2] != "vendor" {
+			log.Debugf("Recursive call to scanDir starting from: %s going to: %s", fpath, filepath.FromSlash(strings.Join(pak[:len(pak)-1], "/")))
+			license = s.scanDir(filepath.FromSlash(strings.Join(pak[:len(pak)-1], "/")))
+		}
+	}
+
+	if license.license == "" || license.license == UnknownLicense {
+		license.license = UnknownLicense
+		// the texts nothing was recognised in still go in the notices
+		if len(candidates) > 0 {
+			license.file = candidates[0]
+			license.files = candidates
+		}
+	}
+
+	return license
+}
+
+// isAlternativeLicenseFile tells if the slash separated path, relative to the package directory,
+// follows the dual licensing convention of naming each license file after its license
+func isAlternativeLicenseFile(rel string) bool {
+	name := strings.ToLower(rel)
+	return strings.HasPrefix(name, "license-") || strings.HasPrefix(name, "licence-")
+}
+
+// scanText returns the coverage of a license text, identical texts are only checked once and
+// looked up in the on-disk cache first
+func (s *licenseScanner) scanText(text []byte) licensecheck.Coverage {
+	s.mu.Lock()
+	t, ok := s.texts[string(text)]
+	if !ok {
+		t = &textScan{done: make(chan struct{})}
+		s.texts[string(text)] = t
+	}
+	s.mu.Unlock()
+
+	if ok {
+		<-t.done
+		return t.coverage
+	}
+
+	t.coverage = s.checkText(text)
+	close(t.done)
+	return t.coverage
+}
+
+func (s *licenseScanner) checkText(text []byte) licensecheck.Coverage {
+	if s.cache == nil {
+		return s.checker.Scan(text)
+	}
+
+	if cov, ok := s.cache.get(text); ok {
+		return cov
+	}
+	cov := s.checker.Scan(text)
+	if err := s.cache.put(text, cov); err != nil {
+		log.Debugf("Cannot cache license scan: %s", err)
+	}
+	return cov
+}
@@ -0,0 +1,105 @@
+package wwhrd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/licensecheck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanPackages(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanPackages")
+	defer rm()
+
+	checker, err := licensecheck.NewScanner(licensecheck.BuiltinLicenses())
+	assert.NoError(t, err)
+
+	vendor := filepath.Join(dir, "vendor")
+	list := map[string]bool{
+		"github.com/fake/package":                 true,
+		"github.com/faux/package":                 true,
+		"github.com/fake/nested/inside/a/package": true,
+		"github.com/fake/nested/inside":           true,
+		"github.com/missing/package":              true,
+	}
+
+	s := newLicenseScanner(checker, 75, defaultLicenseFiles(t), &scanErrors{})
+	lics, err := s.scanPackages(vendor, list, 4, s)
+	assert.NoError(t, err)
+
+	assert.Len(t, lics, 4)
+	assert.Equal(t, Detection{
+		License:    "BSD-3-Clause",
+		File:       filepath.Join(vendor, "github.com/fake/nested/LICENSE"),
+		Files:      []string{filepath.Join(vendor, "github.com/fake/nested/LICENSE")},
+		Confidence: lics["github.com/fake/nested/inside"].Confidence,
+		Detector:   LicenseFiles,
+	}, lics["github.com/fake/nested/inside"])
+	assert.Equal(t, lics["github.com/fake/nested/inside"], lics["github.com/fake/nested/inside/a/package"])
+
+	// the parent directories are scanned once, the identical license texts are checked once
+	assert.Contains(t, s.dirs, filepath.Join(vendor, "github.com/fake/nested"))
+	assert.Len(t, s.texts, 1)
+
+	// results don't depend on the number of workers
+	serial := newLicenseScanner(checker, 75, defaultLicenseFiles(t), &scanErrors{})
+	serialLics, err := serial.scanPackages(vendor, list, 1, serial)
+	assert.NoError(t, err)
+	assert.Equal(t, lics, serialLics)
+}
+
+func TestScanPackagesUnknown(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanPackagesUnknown")
+	defer rm()
+
+	checker, err := licensecheck.NewScanner(licensecheck.BuiltinLicenses())
+	assert.NoError(t, err)
+
+	// the license text isn't recognised, it is kept along with the UNKNOWN license
+	vendor := filepath.Join(dir, "vendor")
+	license := filepath.Join(vendor, "github.com/fake/package/LICENSE")
+	assert.NoError(t, ioutil.WriteFile(license, []byte("All rights reserved by Fake.\n"), 0644))
+
+	s := newLicenseScanner(checker, 75, defaultLicenseFiles(t), &scanErrors{})
+	lics, err := s.scanPackages(vendor, map[string]bool{"github.com/fake/package": true, "github.com/missing/package": true}, 1, s)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Detection{
+		"github.com/fake/package": {License: UnknownLicense, File: license, Files: []string{license}},
+	}, lics)
+}
+
+func TestScanPackagesErrors(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScanPackagesErrors")
+	defer rm()
+
+	checker, err := licensecheck.NewScanner(licensecheck.BuiltinLicenses())
+	assert.NoError(t, err)
+
+	vendor := filepath.Join(dir, "vendor")
+	license := filepath.Join(vendor, "github.com/faux/package/COPYING")
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "NONEXISTENT"), license))
+	list := map[string]bool{
+		"github.com/fake/package": true,
+		"github.com/faux/package": true,
+	}
+
+	s := newLicenseScanner(checker, 75, defaultLicenseFiles(t), &scanErrors{})
+	_, err = s.scanPackages(vendor, list, 2, s)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't read license file: open "+license)
+	}
+
+	errs := newScanErrors(true)
+	s = newLicenseScanner(checker, 75, defaultLicenseFiles(t), errs)
+	lics, err := s.scanPackages(vendor, list, 2, s)
+	assert.NoError(t, err)
+	var ie *IncompleteError
+	assert.True(t, errors.As(errs.err(), &ie))
+	// the readable license files are still scanned
+	assert.Equal(t, "BSD-3-Clause", lics["github.com/fake/package"].License)
+	assert.Equal(t, "BSD-3-Clause", lics["github.com/faux/package"].License)
+}
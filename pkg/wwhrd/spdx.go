@@ -0,0 +1,202 @@
+package wwhrd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// licenseExpr is a parsed SPDX license expression, see
+// https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/
+type licenseExpr struct {
+	// license is set on the leaves, a license ID along with its WITH exception if any
+	license string
+	// op is AND or OR, combining the operands
+	op       string
+	operands []*licenseExpr
+}
+
+// parseLicenseExpression parses an SPDX license expression, operators are case-insensitive and
+// AND binds tighter than OR
+func parseLicenseExpression(s string) (*licenseExpr, error) {
+	p := &exprParser{tokens: tokenizeExpression(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %s", s, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", s, p.tokens[p.pos])
+	}
+	return e, nil
+}
+
+func tokenizeExpression(s string) []string {
+	s = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)
+	return strings.Fields(s)
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) isOp(op string) bool {
+	return strings.EqualFold(p.peek(), op)
+}
+
+func (p *exprParser) parseOr() (*licenseExpr, error) {
+	return p.parseOp("OR", p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (*licenseExpr, error) {
+	return p.parseOp("AND", p.parseWith)
+}
+
+// parseOp parses the operands of op, each of them being parsed by next
+func (p *exprParser) parseOp(op string, next func() (*licenseExpr, error)) (*licenseExpr, error) {
+	e, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp(op) {
+		return e, nil
+	}
+
+	expr := &licenseExpr{op: op, operands: []*licenseExpr{e}}
+	for p.isOp(op) {
+		p.pos++
+		if e, err = next(); err != nil {
+			return nil, err
+		}
+		expr.operands = append(expr.operands, e)
+	}
+	return expr, nil
+}
+
+func (p *exprParser) parseWith() (*licenseExpr, error) {
+	paren := p.peek() == "("
+	e, err := p.parsePrimary()
+	if err != nil || !p.isOp("WITH") {
+		return e, err
+	}
+	if paren {
+		return nil, fmt.Errorf("WITH must follow a license")
+	}
+	p.pos++
+	exception := p.peek()
+	if !isLicenseID(exception) {
+		return nil, fmt.Errorf("WITH must be followed by an exception")
+	}
+	p.pos++
+	e.license += " WITH " + exception
+	return e, nil
+}
+
+func (p *exprParser) parsePrimary() (*licenseExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end")
+	case tok == "(":
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return e, nil
+	case isLicenseID(tok):
+		p.pos++
+		return &licenseExpr{license: tok}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok)
+}
+
+// isLicenseID tells if tok can be a license ID rather than an operator or a parenthesis
+func isLicenseID(tok string) bool {
+	switch strings.ToUpper(tok) {
+	case "", "(", ")", "AND", "OR", "WITH":
+		return false
+	}
+	return true
+}
+
+// approved tells if the expression is approved, given the approval of each license
+func (e *licenseExpr) approved(license func(id string) bool) bool {
+	switch e.op {
+	case "AND":
+		for _, o := range e.operands {
+			if !o.approved(license) {
+				return false
+			}
+		}
+		return true
+	case "OR":
+		for _, o := range e.operands {
+			if o.approved(license) {
+				return true
+			}
+		}
+		return false
+	}
+	return license(e.license)
+}
+
+// licenses returns the IDs of the licenses and exceptions of the expression, in order
+func (e *licenseExpr) licenses() []string {
+	if e.op == "" {
+		return strings.Split(e.license, " WITH ")
+	}
+	var ids []string
+	for _, o := range e.operands {
+		ids = append(ids, o.licenses()...)
+	}
+	return ids
+}
+
+// joinLicenses returns the expression of a package under all of licenses, in lexical order and
+// without duplicates, compound expressions being parenthesized
+func joinLicenses(licenses []string) string {
+	return combineLicenses(licenses, "AND")
+}
+
+// anyLicense returns the expression of a package under the license of its choice among licenses,
+// as joinLicenses does
+func anyLicense(licenses []string) string {
+	return combineLicenses(licenses, "OR")
+}
+
+func combineLicenses(licenses []string, op string) string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, l := range licenses {
+		if !seen[l] {
+			seen[l] = true
+			ids = append(ids, l)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) == 1 {
+		return ids[0]
+	}
+
+	for i, l := range ids {
+		if len(tokenizeExpression(l)) > 1 {
+			ids[i] = "(" + l + ")"
+		}
+	}
+	return strings.Join(ids, " "+op+" ")
+}
@@ -0,0 +1,52 @@
+package wwhrd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	e, err := parseLicenseExpression("MIT")
+	assert.NoError(t, err)
+	assert.Equal(t, &licenseExpr{license: "MIT"}, e)
+
+	// AND binds tighter than OR, operators are case-insensitive
+	e, err = parseLicenseExpression("MIT or Apache-2.0 AND (ISC OR BSD-3-Clause) OR GPL-2.0-only WITH Classpath-exception-2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, &licenseExpr{op: "OR", operands: []*licenseExpr{
+		{license: "MIT"},
+		{op: "AND", operands: []*licenseExpr{
+			{license: "Apache-2.0"},
+			{op: "OR", operands: []*licenseExpr{{license: "ISC"}, {license: "BSD-3-Clause"}}},
+		}},
+		{license: "GPL-2.0-only WITH Classpath-exception-2.0"},
+	}}, e)
+
+	assert.Equal(t, []string{"MIT", "Apache-2.0", "ISC", "BSD-3-Clause", "GPL-2.0-only", "Classpath-exception-2.0"}, e.licenses())
+
+	for expr, msg := range map[string]string{
+		"":              "empty license expression",
+		"MIT OR":        `invalid license expression "MIT OR": unexpected end`,
+		"(MIT OR ISC":   `invalid license expression "(MIT OR ISC": missing )`,
+		"MIT ISC":       `invalid license expression "MIT ISC": unexpected "ISC"`,
+		"MIT WITH":      `invalid license expression "MIT WITH": WITH must be followed by an exception`,
+		"(MIT) WITH CE": `invalid license expression "(MIT) WITH CE": WITH must follow a license`,
+		"AND MIT":       `invalid license expression "AND MIT": unexpected "AND"`,
+	} {
+		_, err := parseLicenseExpression(expr)
+		assert.EqualError(t, err, msg, expr)
+	}
+}
+
+func TestJoinLicenses(t *testing.T) {
+	assert.Equal(t, "", joinLicenses(nil))
+	assert.Equal(t, "MIT OR ISC", joinLicenses([]string{"MIT OR ISC", "MIT OR ISC"}))
+	assert.Equal(t, "Apache-2.0 AND BSD-3-Clause AND (MIT OR ISC)", joinLicenses([]string{"BSD-3-Clause", "MIT OR ISC", "Apache-2.0", "BSD-3-Clause"}))
+}
+
+func TestAnyLicense(t *testing.T) {
+	assert.Equal(t, "MIT", anyLicense([]string{"MIT", "MIT"}))
+	assert.Equal(t, "Apache-2.0 OR MIT", anyLicense([]string{"MIT", "Apache-2.0"}))
+	assert.Equal(t, "(Apache-2.0 AND ISC) OR MIT", anyLicense([]string{"MIT", "Apache-2.0 AND ISC"}))
+}
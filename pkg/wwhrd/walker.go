@@ -0,0 +1,507 @@
+package wwhrd
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/licensecheck"
+	log "github.com/sirupsen/logrus"
+)
+
+// UnknownLicense is the license of the packages no detector could tell the license of
+const UnknownLicense = "UNKNOWN"
+
+var (
+	// FileNames used to search for licenses
+	// "LICENSE.docs" and "LICENCE.docs" are excluded from the list as we only care about source code in the repo.
+	FileNames = []string{
+		"COPYING",
+		"COPYING.md",
+		"COPYING.markdown",
+		"COPYING.txt",
+		"LICENCE",
+		"LICENCE.md",
+		"LICENCE.markdown",
+		"LICENCE.txt",
+		"LICENSE",
+		"LICENSE.md",
+		"LICENSE.markdown",
+		"LICENSE.txt",
+		"LICENSE-2.0.txt",
+		"LICENCE-2.0.txt",
+		"LICENSE-APACHE",
+		"LICENCE-APACHE",
+		"LICENSE-APACHE-2.0.txt",
+		"LICENCE-APACHE-2.0.txt",
+		"LICENSE-MIT",
+		"LICENCE-MIT",
+		"LICENSE.MIT",
+		"LICENCE.MIT",
+		"LICENSE.code",
+		"LICENCE.code",
+		"LICENSE.rst",
+		"LICENCE.rst",
+		"MIT-LICENSE",
+		"MIT-LICENCE",
+		"MIT-LICENSE.md",
+		"MIT-LICENCE.md",
+		"MIT-LICENSE.markdown",
+		"MIT-LICENCE.markdown",
+		"MIT-LICENSE.txt",
+		"MIT-LICENCE.txt",
+		"MIT_LICENSE",
+		"MIT_LICENCE",
+		"UNLICENSE",
+		"UNLICENCE",
+	}
+)
+
+// RootPackage is the name of the project itself in the Graph
+const RootPackage = "root"
+
+// Graph is the directed graph of the imports of the project and of its vendored packages,
+// edges go from the importer to the imported package. It can be read concurrently.
+type Graph struct {
+	nodes     []*node
+	nodesList map[string]bool
+	edges     map[string]map[string][]Import
+	checkTest bool
+	mu        sync.RWMutex
+}
+
+// Import is an import of a vendored package, with the location of the import statement,
+// File being relative to the root of the project
+type Import struct {
+	From string
+	To   string
+	File string
+	Line int
+}
+
+type node struct {
+	pkg    string
+	dir    string
+	vendor string
+}
+
+// NewGraph returns an empty graph, to be filled with AddPackage and AddImport
+func NewGraph() *Graph {
+	return newGraph(false)
+}
+
+func newGraph(checkTest bool) *Graph {
+	var g Graph
+	g.nodesList = make(map[string]bool)
+	g.edges = make(map[string]map[string][]Import)
+	g.checkTest = checkTest
+	return &g
+}
+
+// Has reports whether pkg is in the graph
+func (g *Graph) Has(pkg string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.nodesList[pkg]
+}
+
+// Packages returns the packages of the graph, RootPackage first and the others sorted
+func (g *Graph) Packages() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	pkgs := make([]string, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		pkgs = append(pkgs, n.pkg)
+	}
+	return pkgs
+}
+
+// Imports returns the edges of the graph, sorted by importer and imported package,
+// each edge carries the location of the first import statement in file order
+func (g *Graph) Imports() []Import {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var edges []Import
+	for _, to := range g.edges {
+		for _, statements := range to {
+			edges = append(edges, statements[0])
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// Statements returns every import statement of to found in from, sorted by file and line
+func (g *Graph) Statements(from, to string) []Import {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]Import(nil), g.edges[from][to]...)
+}
+
+// AddNode adds a node to the graph
+func (g *Graph) addNode(n *node) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// check if Node has been visited, checking and adding under the same lock so that
+	// concurrent walkers never add a node twice
+	if !g.nodesList[n.pkg] {
+		g.nodes = append(g.nodes, n)
+		g.nodesList[n.pkg] = true
+		return nil
+	}
+	return fmt.Errorf("[%s] node already visited", n.pkg)
+}
+
+// AddPackage adds pkg to the graph, the first package added being the root of the graph
+func (g *Graph) AddPackage(pkg string) {
+	if err := g.addNode(&node{pkg: pkg}); err != nil {
+		log.Debug(err.Error())
+	}
+}
+
+// AddImport adds a directed edge to the graph, every import statement between the same two packages
+// is kept, sorted by file and line
+func (g *Graph) AddImport(e Import) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.edges[e.From] == nil {
+		g.edges[e.From] = make(map[string][]Import)
+	}
+	statements := g.edges[e.From][e.To]
+	i := sort.Search(len(statements), func(i int) bool {
+		return statements[i].File > e.File || statements[i].File == e.File && statements[i].Line >= e.Line
+	})
+	if i < len(statements) && statements[i] == e {
+		return
+	}
+	statements = append(statements, Import{})
+	copy(statements[i+1:], statements[i:])
+	statements[i] = e
+	g.edges[e.From][e.To] = statements
+}
+
+// fileImport is an import statement found in a go file
+type fileImport struct {
+	file string
+	pkg  string
+	line int
+}
+
+// dirListing is the content of a directory relevant to the walk, done is closed once it is known
+type dirListing struct {
+	done    chan struct{}
+	imports []fileImport
+	subdirs []string
+	err     error
+}
+
+// importWalker walks the imports of a project with a pool of workers. Packages are walked once,
+// and so are directories.
+type importWalker struct {
+	g    *Graph
+	jobs int
+	errs *scanErrors
+
+	mu   sync.Mutex
+	dirs map[string]*dirListing
+}
+
+// walk walks the graph from the root node until every reachable vendored package has been walked,
+// the context is cancelled or a worker fails
+func (w *importWalker) walk(ctx context.Context, root *node) error {
+	var (
+		mu     sync.Mutex
+		cond   = sync.NewCond(&mu)
+		queue  = []*node{root}
+		active int
+		failed error
+	)
+
+	worker := func() {
+		for {
+			mu.Lock()
+			for len(queue) == 0 && active > 0 && failed == nil {
+				cond.Wait()
+			}
+			if len(queue) == 0 || failed != nil {
+				mu.Unlock()
+				return
+			}
+			n := queue[0]
+			queue = queue[1:]
+			active++
+			mu.Unlock()
+
+			log.Debugf("[%s] walking node", n.pkg)
+			found, err := w.walkNode(ctx, n)
+
+			mu.Lock()
+			active--
+			queue = append(queue, found...)
+			if err != nil && failed == nil {
+				failed = err
+			}
+			cond.Broadcast()
+			mu.Unlock()
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker()
+		}()
+	}
+	wg.Wait()
+
+	return failed
+}
+
+// walkNode records the imports of the go files of a node and returns the vendored packages
+// walked for the first time. The root node is the whole project, a vendored package is only the
+// go files of its directory, its subdirectories being packages of their own. Unreadable paths are
+// only returned as an error when not keeping going.
+func (w *importWalker) walkNode(ctx context.Context, n *node) ([]*node, error) {
+	info, err := os.Lstat(n.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, w.errs.add(fmt.Errorf("can't read directory: %s", err))
+	}
+	if _, err := shouldSkip(n.dir, info, w.g.checkTest); err != nil {
+		return nil, nil
+	}
+
+	var found []*node
+	dirs := []string{n.dir}
+	for len(dirs) > 0 {
+		if err := ctx.Err(); err != nil {
+			return found, err
+		}
+
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+
+		l := w.listDir(dir)
+		if l.err != nil {
+			return found, l.err
+		}
+		// walk the subdirectories of the project in lexical order
+		if n.pkg == RootPackage {
+			for i := len(l.subdirs) - 1; i >= 0; i-- {
+				dirs = append(dirs, l.subdirs[i])
+			}
+		}
+
+		for _, imp := range l.imports {
+			pkgdir := filepath.Join(n.vendor, "vendor", imp.pkg)
+			if _, err := os.Stat(pkgdir); os.IsNotExist(err) {
+				continue
+			}
+
+			// record the import edge, even when the imported node was already visited
+			if imp.pkg != n.pkg {
+				file, err := filepath.Rel(n.vendor, imp.file)
+				if err != nil {
+					file = imp.file
+				}
+				w.g.AddImport(Import{From: n.pkg, To: imp.pkg, File: filepath.ToSlash(file), Line: imp.line})
+			}
+
+			// Add imported pkg to the graph
+			var vendornode = node{pkg: imp.pkg, dir: pkgdir, vendor: n.vendor}
+			log.Debugf("[%s] adding node", vendornode.pkg)
+			if err := w.g.addNode(&vendornode); err != nil {
+				log.Debug(err.Error())
+				continue
+			}
+			found = append(found, &vendornode)
+		}
+	}
+
+	return found, nil
+}
+
+// listDir returns the imports and the subdirectories of a directory, reading it only once so that
+// its errors are only recorded once
+func (w *importWalker) listDir(dir string) *dirListing {
+	w.mu.Lock()
+	l, ok := w.dirs[dir]
+	if !ok {
+		l = &dirListing{done: make(chan struct{})}
+		w.dirs[dir] = l
+	}
+	w.mu.Unlock()
+
+	if ok {
+		<-l.done
+		return l
+	}
+
+	l.imports, l.subdirs, l.err = w.readDir(dir)
+	close(l.done)
+	return l
+}
+
+func (w *importWalker) readDir(dir string) ([]fileImport, []string, error) {
+	log.Debugf("walking %q", dir)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, nil, w.errs.add(fmt.Errorf("can't read directory: %s", err))
+	}
+
+	var imports []fileImport
+	var subdirs []string
+	for _, info := range files {
+		path := filepath.Join(dir, info.Name())
+
+		// check if we need to skip this
+		if ok, err := shouldSkip(path, info, w.g.checkTest); ok {
+			if info.IsDir() && err == nil {
+				subdirs = append(subdirs, path)
+			}
+			continue
+		}
+
+		fs := token.NewFileSet()
+		f, err := parser.ParseFile(fs, path, nil, parser.ImportsOnly)
+		if err != nil {
+			if err := w.errs.add(fmt.Errorf("can't parse go file: %s", err)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		for _, s := range f.Imports {
+			vendorpkg := strings.Replace(s.Path.Value, "\"", "", -1)
+			log.Debugf("found import %q", vendorpkg)
+			imports = append(imports, fileImport{file: path, pkg: vendorpkg, line: fs.Position(s.Pos()).Line})
+		}
+	}
+
+	return imports, subdirs, nil
+}
+
+// walkGraph walks the imports of the project and returns the resulting dependency graph,
+// nodes are sorted by package, after the root node. Unreadable paths are recorded in errs.
+func walkGraph(ctx context.Context, opts Options, errs *scanErrors) (*Graph, error) {
+
+	graph := newGraph(opts.CheckTestFiles)
+	rootNode := node{pkg: RootPackage, dir: opts.Root, vendor: opts.Root}
+	if err := graph.addNode(&rootNode); err != nil {
+		log.Debug(err.Error())
+	}
+
+	log.Debugf("[%s] walking root node", rootNode.pkg)
+	w := &importWalker{g: graph, jobs: opts.jobs(), errs: errs, dirs: make(map[string]*dirListing)}
+	if err := w.walk(ctx, &rootNode); err != nil {
+		return nil, fmt.Errorf("can't walk imports: %w", err)
+	}
+
+	sort.SliceStable(graph.nodes[1:], func(i, j int) bool {
+		return graph.nodes[i+1].pkg < graph.nodes[j+1].pkg
+	})
+
+	return graph, nil
+}
+
+// licenseInfo describes the license files found for a directory, license being the expression of
+// all of them and coverage the lowest of them
+type licenseInfo struct {
+	license  string
+	file     string
+	files    []string
+	coverage float64
+}
+
+// detectLicenses returns the license of each package of list, as found by the detectors of opts
+// and then by the license files. Unreadable paths are recorded in errs.
+func detectLicenses(opts Options, list map[string]bool, errs *scanErrors) (map[string]Detection, error) {
+
+	var custom []licensecheck.License
+	if opts.Config != nil && opts.Config.Licenses != "" {
+		var err error
+		if custom, err = ReadCustomLicenses(opts.Config.Licenses); err != nil {
+			return nil, err
+		}
+	}
+
+	checker, err := newChecker(custom)
+	if err != nil {
+		if len(custom) > 0 {
+			return nil, &ConfigError{Err: err}
+		}
+		return nil, err
+	}
+
+	root := opts.Root
+	if !strings.HasSuffix(root, "vendor") {
+		root = filepath.Join(root, "vendor")
+	}
+	log.Debug("Start walking paths for LICENSE discovery")
+
+	// the matcher is built for each scan, following the changes of FileNames and LicenseFilePatterns
+	var patterns []string
+	if opts.Config != nil {
+		patterns = opts.Config.LicenseFiles
+	}
+	files, err := newLicenseFiles(patterns)
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	// overrides come first, they are decisions taken over any detection
+	var detectors []Detector
+	if opts.Config != nil && len(opts.Config.Overrides) > 0 {
+		o, err := newOverrideDetector(root, files, opts.Config.Overrides)
+		if err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+		detectors = append(detectors, o)
+	}
+
+	s := newLicenseScanner(checker, opts.CoverageThreshold, files, errs)
+	s.cache = openCache(opts.CacheDir, opts.CoverageThreshold, corpusDigest(custom))
+	// licenses declared in REUSE dep5 files are more specific than the license texts of a project
+	detector := Chain(append(append(detectors, opts.Detectors...), newReuseDetector(root), s)...)
+	return s.scanPackages(root, list, opts.jobs(), detector)
+}
+
+func shouldSkip(path string, info os.FileInfo, checkTest bool) (bool, error) {
+	if info.IsDir() {
+		name := info.Name()
+		// check if directory is in the blocklist
+		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+			log.Debugf("skipping %q: directory in blocklist", path)
+			return true, filepath.SkipDir
+		}
+		return true, nil
+	}
+	// if it's not a .go file, skip
+	if filepath.Ext(path) != ".go" {
+		log.Debugf("skipping %q: not a go file", path)
+		return true, nil
+	}
+	// if it's a test file, skip
+	if strings.HasSuffix(path, "_test.go") && !checkTest {
+		log.Debugf("skipping %q: test file", path)
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,191 @@
+package wwhrd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestWalk")
+	defer rm()
+
+	graph, err := Walk(context.Background(), Options{Root: dir})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"root", "github.com/fake/nested/inside/a/package", "github.com/fake/package"}, graph.Packages())
+	assert.True(t, graph.Has("github.com/fake/package"))
+	assert.False(t, graph.Has("github.com/this/does/not/exist"))
+}
+
+func TestWalkImportsRecordsImports(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestWalkImportsRecordsImports")
+	defer rm()
+
+	graph, err := Walk(context.Background(), Options{Root: dir})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Import{
+		{From: "root", To: "github.com/fake/package", File: "mockpkg.go", Line: 3},
+		{From: "root", To: "github.com/fake/nested/inside/a/package", File: "mockpkg.go", Line: 4},
+	}, graph.Imports())
+}
+
+func TestScan(t *testing.T) {
+	dir, rm := mockGoPackageDir(t, "TestScan")
+	defer rm()
+
+	res, err := Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75, Config: &Config{Allowlist: []string{"BSD-3-Clause"}}})
+	assert.NoError(t, err)
+
+	lics := make(map[string]string)
+	for _, p := range res.Packages {
+		lics[p.Package] = p.License
+		assert.Equal(t, DecisionApproved, p.Decision)
+	}
+	assert.Equal(t, map[string]string{
+		"github.com/fake/package":                 "BSD-3-Clause",
+		"github.com/fake/nested/inside/a/package": "BSD-3-Clause",
+	}, lics)
+	assert.NoError(t, res.Check())
+
+	res, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75, Config: &Config{Denylist: []string{"BSD-3-Clause"}}})
+	assert.NoError(t, err)
+	assert.Len(t, res.Denied(), 2)
+	assert.Equal(t, ErrNonApprovedLicense, res.Check())
+
+	// only the included packages are scanned
+	include := func(pkg string) bool { return pkg == "github.com/fake/package" }
+	res, err = Scan(context.Background(), Options{Root: dir, CoverageThreshold: 75, Include: include})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Packages, 1) {
+		assert.Equal(t, "github.com/fake/package", res.Packages[0].Package)
+		assert.Equal(t, Decision(""), res.Packages[0].Decision)
+	}
+}
+
+// mockNestedPackages lays out vendored packages nested in each other, importing each other
+func mockNestedPackages(t *testing.T, dir string) {
+	files := map[string]string{
+		"main.go":                          "package main\nimport (\n\t\"github.com/a/b\"\n\t\"github.com/a/b/c\"\n)\n",
+		"vendor/github.com/a/b/b.go":       "package b\nimport \"github.com/a/b/c\"\n",
+		"vendor/github.com/a/b/c/c.go":     "package c\nimport \"github.com/d/e\"\n",
+		"vendor/github.com/d/e/e.go":       "package e\nimport \"github.com/a/b\"\n",
+		"vendor/github.com/d/e/_ex/ex.go":  "package ex\nimport \"github.com/f/g\"\n",
+		"vendor/github.com/f/g/g.go":       "package g\n",
+		"vendor/github.com/a/b/c/LICENSE":  mockLicense,
+		"vendor/github.com/d/e/testdata/x": "",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestImportWalker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestImportWalker")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockNestedPackages(t, dir)
+
+	var graphs []*Graph
+	for _, jobs := range []int{1, 8} {
+		g := newGraph(false)
+		root := node{pkg: "root", dir: dir, vendor: dir}
+		assert.NoError(t, g.addNode(&root))
+
+		w := &importWalker{g: g, jobs: jobs, errs: &scanErrors{}, dirs: make(map[string]*dirListing)}
+		assert.NoError(t, w.walk(context.Background(), &root))
+
+		// the project and each package directory are read once
+		assert.Len(t, w.dirs, 4)
+		assert.Contains(t, w.dirs, filepath.Join(dir, "vendor/github.com/a/b/c"))
+		graphs = append(graphs, g)
+	}
+
+	assert.Equal(t, map[string]bool{"root": true, "github.com/a/b": true, "github.com/a/b/c": true, "github.com/d/e": true}, graphs[0].nodesList)
+	assert.Equal(t, graphs[0].Imports(), graphs[1].Imports())
+	// imports are attributed to the package of the file, not to its parents
+	assert.Equal(t, []Import{
+		{From: "github.com/a/b", To: "github.com/a/b/c", File: "vendor/github.com/a/b/b.go", Line: 2},
+		{From: "github.com/a/b/c", To: "github.com/d/e", File: "vendor/github.com/a/b/c/c.go", Line: 2},
+		{From: "github.com/d/e", To: "github.com/a/b", File: "vendor/github.com/d/e/e.go", Line: 2},
+	}, graphs[0].Imports()[:3])
+}
+
+func TestWalkGraphCancelled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestWalkGraphCancelled")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockNestedPackages(t, dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = Walk(ctx, Options{Root: dir})
+	assert.EqualError(t, err, "can't walk imports: context canceled")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, err = Walk(ctx, Options{Root: dir})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestWalkGraphSortsNodes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestWalkGraphSortsNodes")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockNestedPackages(t, dir)
+
+	graph, err := Walk(context.Background(), Options{Root: dir})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"root", "github.com/a/b", "github.com/a/b/c", "github.com/d/e"}, graph.Packages())
+}
+
+func TestWalkGraphNestedPackages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestWalkGraphNestedPackages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockNestedPackages(t, dir)
+
+	// github.com/a/b/sub isn't imported, nor are its imports
+	sub := filepath.Join(dir, "vendor/github.com/a/b/sub")
+	assert.NoError(t, os.Mkdir(sub, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sub, "s.go"), []byte("package sub\nimport \"github.com/f/g\"\n"), 0644))
+
+	graph, err := Walk(context.Background(), Options{Root: dir})
+	assert.NoError(t, err)
+	assert.False(t, graph.Has("github.com/a/b/sub"))
+	assert.False(t, graph.Has("github.com/f/g"))
+	assert.Empty(t, graph.Statements("github.com/a/b", "github.com/f/g"))
+}
+
+func TestWalkGraphErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestWalkGraphErrors")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockNestedPackages(t, dir)
+
+	broken := filepath.Join(dir, "vendor/github.com/d/e/broken.go")
+	assert.NoError(t, ioutil.WriteFile(broken, []byte("package e\nimport (\n"), 0644))
+
+	_, err = Walk(context.Background(), Options{Root: dir})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't walk imports: can't parse go file: "+broken)
+		assert.True(t, errors.Is(err, ErrIncompleteScan))
+	}
+
+	graph, err := Walk(context.Background(), Options{Root: dir, KeepGoing: true})
+	var ie *IncompleteError
+	if assert.True(t, errors.As(err, &ie)) {
+		assert.Len(t, ie.Errs, 1)
+	}
+	// the rest of the package is still walked
+	assert.True(t, graph.Has("github.com/d/e"))
+	assert.Contains(t, graph.Imports(), Import{From: "github.com/d/e", To: "github.com/a/b", File: "vendor/github.com/d/e/e.go", Line: 2})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path"
+	"sort"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// ReverseDependency is a package depending, directly or transitively, on another package
+type ReverseDependency struct {
+	Package string
+	Direct  bool
+	// Local is set for the packages of the project itself, as opposed to vendored ones
+	Local bool
+}
+
+// reverseDependencies returns the packages depending on target, sorted with local packages first.
+// Local packages are named after modulePath and the directory of the importing files.
+func reverseDependencies(g *wwhrd.Graph, target, modulePath string) []ReverseDependency {
+	edges := g.Imports()
+
+	v := &graphView{edges: edges}
+	ancestors := v.ancestors([]string{target})
+
+	deps := make(map[string]*ReverseDependency)
+	add := func(pkg string, direct, local bool) {
+		if d, ok := deps[pkg]; ok {
+			d.Direct = d.Direct || direct
+			return
+		}
+		deps[pkg] = &ReverseDependency{Package: pkg, Direct: direct, Local: local}
+	}
+
+	for _, e := range edges {
+		if !ancestors[e.To] || e.From == target {
+			continue
+		}
+		direct := e.To == target
+		if e.From != wwhrd.RootPackage {
+			add(e.From, direct, false)
+			continue
+		}
+		// the root node stands for every package of the project, tell them apart by directory
+		for _, s := range g.Statements(e.From, e.To) {
+			add(localPackage(modulePath, s.File), direct, true)
+		}
+	}
+
+	var list []ReverseDependency
+	for _, d := range deps {
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Local != list[j].Local {
+			return list[i].Local
+		}
+		return list[i].Package < list[j].Package
+	})
+	return list
+}
+
+// localPackage returns the import path of the package holding file, relative to the project root
+func localPackage(modulePath, file string) string {
+	dir := path.Dir(file)
+	switch {
+	case modulePath == "":
+		if dir == "." {
+			return "."
+		}
+		return "./" + dir
+	case dir == ".":
+		return modulePath
+	}
+	return modulePath + "/" + dir
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseDependencies(t *testing.T) {
+	graph := wwhrd.NewGraph()
+	for _, e := range []wwhrd.Import{
+		{From: "root", To: "a", File: "main.go", Line: 3},
+		{From: "root", To: "a", File: "cmd/tool/tool.go", Line: 4},
+		{From: "root", To: "b", File: "internal/b.go", Line: 5},
+		{From: "root", To: "d", File: "internal/d.go", Line: 5},
+		{From: "a", To: "c", File: "vendor/a/a.go", Line: 3},
+		{From: "b", To: "a", File: "vendor/b/b.go", Line: 3},
+		{From: "c", To: "d", File: "vendor/c/c.go", Line: 3},
+	} {
+		graph.AddImport(e)
+	}
+
+	assert.Equal(t, []ReverseDependency{
+		{Package: "example.com/mod", Local: true},
+		{Package: "example.com/mod/cmd/tool", Local: true},
+		{Package: "example.com/mod/internal", Local: true},
+		{Package: "a", Direct: true},
+		{Package: "b"},
+	}, reverseDependencies(graph, "c", "example.com/mod"))
+
+	// local packages are relative to the root without a module path
+	assert.Equal(t, []ReverseDependency{
+		{Package: "./internal", Direct: true, Local: true},
+	}, reverseDependencies(graph, "b", ""))
+
+	assert.Empty(t, reverseDependencies(graph, "root", ""))
+}
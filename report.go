@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// ComplianceReport is the data rendered by the report formats
+type ComplianceReport struct {
+	Licenses   []Count
+	Decisions  []Count
+	Modules    []ModuleReport
+	Exceptions []wwhrd.PackageResult
+	Failures   []wwhrd.PackageResult
+}
+
+// Count is a row of a summary table
+type Count struct {
+	Name  string
+	Count int
+}
+
+// ModuleReport groups the results of the packages provided by the same module
+type ModuleReport struct {
+	Module   string
+	Version  string
+	Packages []wwhrd.PackageResult
+}
+
+// NewComplianceReport summarizes results, which must be sorted by package
+func NewComplianceReport(results []wwhrd.PackageResult) *ComplianceReport {
+	r := &ComplianceReport{}
+
+	licenses := make(map[string]int)
+	decisions := make(map[string]int)
+	modules := make(map[string]*ModuleReport)
+	var order []string
+
+	for _, res := range results {
+		licenses[res.License]++
+		decisions[string(res.Decision)]++
+
+		// packages without module information are reported on their own
+		name := res.Module
+		if name == "" {
+			name = res.Package
+		}
+		m, ok := modules[name]
+		if !ok {
+			m = &ModuleReport{Module: name, Version: res.Version}
+			modules[name] = m
+			order = append(order, name)
+		}
+		m.Packages = append(m.Packages, res)
+
+		switch res.Decision {
+		case wwhrd.DecisionExceptioned:
+			r.Exceptions = append(r.Exceptions, res)
+		case wwhrd.DecisionDenied:
+			r.Failures = append(r.Failures, res)
+		}
+	}
+
+	r.Licenses = sortedCounts(licenses)
+	r.Decisions = sortedCounts(decisions)
+
+	sort.Strings(order)
+	for _, name := range order {
+		r.Modules = append(r.Modules, *modules[name])
+	}
+
+	return r
+}
+
+func sortedCounts(m map[string]int) []Count {
+	counts := make([]Count, 0, len(m))
+	for k, v := range m {
+		counts = append(counts, Count{Name: k, Count: v})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// RenderReport writes the report in the given format, either html or markdown
+func RenderReport(w io.Writer, r *ComplianceReport, format string) error {
+	switch format {
+	case "html":
+		t, err := htmltemplate.New("report").Parse(htmlReportTemplate)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, r)
+	case "markdown", "md":
+		t, err := template.New("report").Funcs(template.FuncMap{
+			"cell": markdownCell,
+		}).Parse(markdownReportTemplate)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, r)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// markdownCell escapes a value so it can be safely used in a table cell
+func markdownCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+const markdownReportTemplate = `# License compliance report
+
+## Summary
+
+| License | Packages |
+| --- | ---: |
+{{- range .Licenses}}
+| {{cell .Name}} | {{.Count}} |
+{{- end}}
+
+| Decision | Packages |
+| --- | ---: |
+{{- range .Decisions}}
+| {{cell .Name}} | {{.Count}} |
+{{- end}}
+
+## Modules
+
+| Module | Version | Package | License | Decision | License file |
+| --- | --- | --- | --- | --- | --- |
+{{- range $m := .Modules}}{{range .Packages}}
+| {{cell $m.Module}} | {{cell $m.Version}} | {{cell .Package}} | {{cell .License}} | {{cell (print .Decision)}} | {{if .File}}[{{cell .File}}]({{.File}}){{else}}-{{end}} |
+{{- end}}{{end}}
+
+## Exceptions
+{{if .Exceptions}}
+| Package | License | Exception | Justification |
+| --- | --- | --- | --- |
+{{- range .Exceptions}}
+| {{cell .Package}} | {{cell .License}} | {{cell .Exception}} | {{cell .Justification}} |
+{{- end}}
+{{else}}
+No exceptions.
+{{end}}
+## Failures
+{{if .Failures}}
+| Package | Module | License |
+| --- | --- | --- |
+{{- range .Failures}}
+| {{cell .Package}} | {{cell .Module}} | {{cell .License}} |
+{{- end}}
+{{else}}
+No failures.
+{{end -}}
+`
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>License compliance report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f0f0f0; }
+.approved { color: #1a7f37; }
+.exceptioned { color: #bc4c00; }
+.denied { color: #cf222e; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>License compliance report</h1>
+
+<h2>Summary</h2>
+<table>
+<tr><th>License</th><th>Packages</th></tr>
+{{- range .Licenses}}
+<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>
+<table>
+<tr><th>Decision</th><th>Packages</th></tr>
+{{- range .Decisions}}
+<tr><td class="{{.Name}}">{{.Name}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Modules</h2>
+<table>
+<tr><th>Module</th><th>Version</th><th>Package</th><th>License</th><th>Decision</th><th>License file</th></tr>
+{{- range $m := .Modules}}{{range .Packages}}
+<tr><td>{{$m.Module}}</td><td>{{$m.Version}}</td><td>{{.Package}}</td><td>{{.License}}</td><td class="{{.Decision}}">{{.Decision}}</td><td>{{if .File}}<a href="{{.File}}">{{.File}}</a>{{end}}</td></tr>
+{{- end}}{{end}}
+</table>
+
+<h2>Exceptions</h2>
+{{- if .Exceptions}}
+<table>
+<tr><th>Package</th><th>License</th><th>Exception</th><th>Justification</th></tr>
+{{- range .Exceptions}}
+<tr><td>{{.Package}}</td><td>{{.License}}</td><td>{{.Exception}}</td><td>{{.Justification}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No exceptions.</p>
+{{- end}}
+
+<h2>Failures</h2>
+{{- if .Failures}}
+<table>
+<tr><th>Package</th><th>Module</th><th>License</th></tr>
+{{- range .Failures}}
+<tr><td class="denied">{{.Package}}</td><td>{{.Module}}</td><td>{{.License}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No failures.</p>
+{{- end}}
+</body>
+</html>
+`
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockResults = []wwhrd.PackageResult{
+	{Package: "github.com/a/b", Module: "github.com/a/b", Version: "v1.0.0", License: "MIT", File: "vendor/github.com/a/b/LICENSE", Decision: wwhrd.DecisionApproved},
+	{Package: "github.com/a/b/c", Module: "github.com/a/b", Version: "v1.0.0", License: "MIT", File: "vendor/github.com/a/b/LICENSE", Decision: wwhrd.DecisionApproved},
+	{Package: "github.com/d/e", License: "GPL-2.0", Decision: wwhrd.DecisionExceptioned, Exception: "github.com/d/...", Justification: "only used in tooling"},
+	{Package: "github.com/f/g", Module: "github.com/f/g", License: "UNKNOWN", Decision: wwhrd.DecisionDenied},
+}
+
+func TestNewComplianceReport(t *testing.T) {
+	r := NewComplianceReport(mockResults)
+
+	assert.Equal(t, []Count{{"MIT", 2}, {"GPL-2.0", 1}, {"UNKNOWN", 1}}, r.Licenses)
+	assert.Equal(t, []Count{{"approved", 2}, {"denied", 1}, {"exceptioned", 1}}, r.Decisions)
+
+	if assert.Len(t, r.Modules, 3) {
+		assert.Equal(t, "github.com/a/b", r.Modules[0].Module)
+		assert.Len(t, r.Modules[0].Packages, 2)
+		// packages without a module are reported on their own
+		assert.Equal(t, "github.com/d/e", r.Modules[1].Module)
+	}
+
+	assert.Equal(t, []wwhrd.PackageResult{mockResults[2]}, r.Exceptions)
+	assert.Equal(t, []wwhrd.PackageResult{mockResults[3]}, r.Failures)
+}
+
+func TestRenderReport(t *testing.T) {
+	r := NewComplianceReport(mockResults)
+	var out bytes.Buffer
+
+	assert.NoError(t, RenderReport(&out, r, "markdown"))
+	assert.Contains(t, out.String(), "| Decision | Packages |\n")
+	assert.Contains(t, out.String(), "| Module | Version | Package | License | Decision | License file |\n")
+	assert.Contains(t, out.String(), "| github.com/a/b | v1.0.0 | github.com/a/b/c | MIT | approved | [vendor/github.com/a/b/LICENSE](vendor/github.com/a/b/LICENSE) |")
+	assert.Contains(t, out.String(), "| github.com/d/e | GPL-2.0 | github.com/d/... | only used in tooling |")
+	assert.Contains(t, out.String(), "| github.com/f/g | github.com/f/g | UNKNOWN |")
+
+	out.Reset()
+	assert.NoError(t, RenderReport(&out, r, "html"))
+	assert.Contains(t, out.String(), "<tr><th>Decision</th><th>Packages</th></tr>")
+	assert.Contains(t, out.String(), "<tr><th>Module</th><th>Version</th><th>Package</th><th>License</th><th>Decision</th><th>License file</th></tr>")
+	assert.Contains(t, out.String(), `<a href="vendor/github.com/a/b/LICENSE">vendor/github.com/a/b/LICENSE</a>`)
+	assert.Contains(t, out.String(), `<tr><td class="denied">github.com/f/g</td><td>github.com/f/g</td><td>UNKNOWN</td></tr>`)
+
+	assert.Error(t, RenderReport(&out, r, "pdf"))
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reporter renders the results of a run
+type Reporter interface {
+	Report(results []wwhrd.PackageResult) error
+}
+
+// reporterOptions configures the reporter built by newReporter
+type reporterOptions struct {
+	// format is one of text, csv, tsv, github, line, html or markdown
+	format string
+	// columns for the csv and tsv formats
+	columns string
+	// template is the path of a Go template, overrides format when set
+	template string
+	// root of the project, used to locate go.mod for annotations
+	root string
+}
+
+// newReporter returns the Reporter writing to w in the format described by o
+func newReporter(w io.Writer, o reporterOptions) (Reporter, error) {
+	if o.template != "" {
+		b, err := ioutil.ReadFile(o.template)
+		if err != nil {
+			return nil, fmt.Errorf("can't read template file: %s", err)
+		}
+		t, err := template.New(o.template).Funcs(templateFuncs).Parse(string(b))
+		if err != nil {
+			return nil, err
+		}
+		return &templateReporter{w: w, tmpl: t}, nil
+	}
+
+	switch o.format {
+	case "", "text":
+		return logReporter{}, nil
+	case "csv", "tsv":
+		columns, err := parseColumns(o.columns)
+		if err != nil {
+			return nil, err
+		}
+		delimiter := ','
+		if o.format == "tsv" {
+			delimiter = '\t'
+		}
+		return &exportReporter{w: w, columns: columns, delimiter: delimiter}, nil
+	case "github", "line":
+		reqs, err := wwhrd.ReadGoModRequires(o.root)
+		if err != nil {
+			return nil, err
+		}
+		file, err := annotationFile(o.root)
+		if err != nil {
+			return nil, err
+		}
+		// annotations are printed on top of the usual log output
+		return multiReporter{logReporter{}, &annotationReporter{w: w, reqs: reqs, file: file, format: o.format}}, nil
+	case "html", "markdown":
+		return &documentReporter{w: w, format: o.format}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", o.format)
+}
+
+// logReporter logs each result, with a level depending on its decision
+type logReporter struct{}
+
+func (logReporter) Report(results []wwhrd.PackageResult) error {
+	for _, r := range results {
+		fields := log.Fields{
+			"package": r.Package,
+			"license": r.License,
+		}
+		// the file the license was derived from
+		if r.File != "" {
+			fields["file"] = r.File
+		}
+		// the file declaring the license, if not a license text
+		if r.Source != "" {
+			fields["source"] = r.Source
+		}
+		contextLogger := log.WithFields(fields)
+
+		switch r.Decision {
+		case "":
+			contextLogger.Info("Found License")
+		case wwhrd.DecisionApproved:
+			contextLogger.Info("Found Approved license")
+		case wwhrd.DecisionExceptioned:
+			contextLogger.Warn("Found exceptioned package")
+		case wwhrd.DecisionBaselined:
+			contextLogger.Warn("Found baselined package")
+		default:
+			contextLogger.Error("Found Non-Approved license")
+		}
+	}
+	return nil
+}
+
+// exportReporter writes results as delimiter separated values
+type exportReporter struct {
+	w         io.Writer
+	columns   []string
+	delimiter rune
+}
+
+func (e *exportReporter) Report(results []wwhrd.PackageResult) error {
+	return ExportResults(e.w, results, e.columns, e.delimiter)
+}
+
+// annotationReporter writes failures as CI annotations
+type annotationReporter struct {
+	w      io.Writer
+	reqs   map[string]*wwhrd.Requirement
+	file   string
+	format string
+}
+
+func (a *annotationReporter) Report(results []wwhrd.PackageResult) error {
+	return WriteAnnotations(a.w, results, a.reqs, a.file, a.format)
+}
+
+// documentReporter renders a compliance report
+type documentReporter struct {
+	w      io.Writer
+	format string
+}
+
+func (d *documentReporter) Report(results []wwhrd.PackageResult) error {
+	return RenderReport(d.w, NewComplianceReport(results), d.format)
+}
+
+// multiReporter sends results to all of its reporters in order
+type multiReporter []Reporter
+
+func (m multiReporter) Report(results []wwhrd.PackageResult) error {
+	for _, r := range m {
+		if err := r.Report(results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TemplateData is the data passed to user supplied templates
+type TemplateData struct {
+	Results []wwhrd.PackageResult
+	Summary *ComplianceReport
+}
+
+// templateReporter renders results with a user supplied template
+type templateReporter struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+func (t *templateReporter) Report(results []wwhrd.PackageResult) error {
+	return t.tmpl.Execute(t.w, TemplateData{
+		Results: results,
+		Summary: NewComplianceReport(results),
+	})
+}
+
+// ResultGroup is a set of results sharing the same value for a field
+type ResultGroup struct {
+	Key     string
+	Results []wwhrd.PackageResult
+}
+
+// templateFuncs are the helper functions available to user supplied templates,
+// fields are referred to by their list --columns name
+var templateFuncs = template.FuncMap{
+	"field":   templateField,
+	"groupBy": groupResults,
+	"sortBy":  sortResults,
+	"filter":  filterResults,
+	"join":    strings.Join,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+}
+
+func fieldFunc(field string) (func(r wwhrd.PackageResult) string, error) {
+	f, ok := exportColumns[strings.ToLower(field)]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	return f, nil
+}
+
+func templateField(field string, r wwhrd.PackageResult) (string, error) {
+	f, err := fieldFunc(field)
+	if err != nil {
+		return "", err
+	}
+	return f(r), nil
+}
+
+// groupResults groups results by the value of field, groups are sorted by key
+func groupResults(field string, results []wwhrd.PackageResult) ([]ResultGroup, error) {
+	f, err := fieldFunc(field)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	var groups []ResultGroup
+	for _, r := range results {
+		key := f(r)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ResultGroup{Key: key})
+		}
+		groups[i].Results = append(groups[i].Results, r)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+// sortResults returns a copy of results sorted by the value of field
+func sortResults(field string, results []wwhrd.PackageResult) ([]wwhrd.PackageResult, error) {
+	f, err := fieldFunc(field)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]wwhrd.PackageResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		switch strings.ToLower(field) {
+		case "coverage":
+			return sorted[i].Coverage < sorted[j].Coverage
+		case "confidence":
+			return sorted[i].Confidence < sorted[j].Confidence
+		}
+		return f(sorted[i]) < f(sorted[j])
+	})
+	return sorted, nil
+}
+
+// filterResults returns the results for which field equals one of values
+func filterResults(field string, values ...interface{}) ([]wwhrd.PackageResult, error) {
+	if len(values) < 2 {
+		return nil, fmt.Errorf("filter expects a field, at least one value and the results")
+	}
+	results, ok := values[len(values)-1].([]wwhrd.PackageResult)
+	if !ok {
+		return nil, fmt.Errorf("filter expects results as last argument, got %T", values[len(values)-1])
+	}
+
+	f, err := fieldFunc(field)
+	if err != nil {
+		return nil, err
+	}
+
+	want := make(map[string]bool)
+	for _, v := range values[:len(values)-1] {
+		want[fmt.Sprint(v)] = true
+	}
+
+	var filtered []wwhrd.PackageResult
+	for _, r := range results {
+		if want[f(r)] {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
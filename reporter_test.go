@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogReporter(t *testing.T) {
+	var out = &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.TextFormatter{DisableColors: true})
+
+	assert.NoError(t, logReporter{}.Report(append(mockResults, wwhrd.PackageResult{Package: "github.com/h/i", License: "MIT"})))
+	assert.Contains(t, out.String(), `level=info msg="Found Approved license" file=vendor/github.com/a/b/LICENSE license=MIT package=github.com/a/b`)
+	assert.Contains(t, out.String(), `level=warning msg="Found exceptioned package" license=GPL-2.0 package=github.com/d/e`)
+	assert.Contains(t, out.String(), `level=error msg="Found Non-Approved license" license=UNKNOWN package=github.com/f/g`)
+	assert.Contains(t, out.String(), `level=info msg="Found License" license=MIT package=github.com/h/i`)
+}
+
+func TestNewReporter(t *testing.T) {
+	var out bytes.Buffer
+
+	for _, format := range []string{"text", "csv", "tsv", "github", "line", "html", "markdown"} {
+		_, err := newReporter(&out, reporterOptions{format: format, columns: "package"})
+		assert.NoError(t, err, format)
+	}
+
+	_, err := newReporter(&out, reporterOptions{format: "xml"})
+	assert.EqualError(t, err, `unknown format "xml"`)
+
+	_, err = newReporter(&out, reporterOptions{template: "NONEXISTENT"})
+	assert.Error(t, err)
+}
+
+func TestTemplateReporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestTemplateReporter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		tmpl string
+		want string
+	}{
+		{
+			`{{range groupBy "license" .Results}}{{.Key}}={{len .Results}};{{end}}`,
+			`GPL-2.0=1;MIT=2;UNKNOWN=1;`,
+		},
+		{
+			`{{range filter "decision" "denied" "exceptioned" .Results}}{{.Package}};{{end}}`,
+			`github.com/d/e;github.com/f/g;`,
+		},
+		{
+			`{{range sortBy "license" .Results}}{{field "license" .}}:{{upper .Package}};{{end}}`,
+			`GPL-2.0:GITHUB.COM/D/E;MIT:GITHUB.COM/A/B;MIT:GITHUB.COM/A/B/C;UNKNOWN:GITHUB.COM/F/G;`,
+		},
+		{
+			`{{range .Summary.Decisions}}{{.Name}}={{.Count}};{{end}}`,
+			`approved=2;denied=1;exceptioned=1;`,
+		},
+	}
+
+	for i, c := range cases {
+		path := filepath.Join(dir, "report.tmpl")
+		assert.NoError(t, ioutil.WriteFile(path, []byte(c.tmpl), 0666))
+
+		var out bytes.Buffer
+		reporter, err := newReporter(&out, reporterOptions{template: path})
+		assert.NoError(t, err)
+		assert.NoError(t, reporter.Report(mockResults), i)
+		assert.Equal(t, c.want, out.String(), i)
+	}
+
+	_, err = groupResults("owner", mockResults)
+	assert.EqualError(t, err, `unknown field "owner"`)
+	_, err = filterResults("decision", mockResults)
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+)
+
+// gitShow returns the content of path, relative to root, at ref in the local git repository,
+// found is false when the file did not exist at ref
+func gitShow(root, ref, path string) (content []byte, found bool, err error) {
+	object := ref + ":./" + path
+
+	cmd := exec.Command("git", "cat-file", "-e", object)
+	cmd.Dir = root
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("can't run git: %s", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd = exec.Command("git", "cat-file", "-p", object)
+	cmd.Dir = root
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, false, fmt.Errorf("can't read %s at %s: %s", path, ref, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, true, nil
+}
+
+// verifyRef checks that ref names a commit of the local git repository
+func verifyRef(root, ref string) error {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = root
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("can't find git ref %q", ref)
+		}
+		return fmt.Errorf("can't run git: %s", err)
+	}
+	return nil
+}
+
+// gitToplevel returns the top directory of the git working tree holding root, found is false when
+// root is not in a git working tree
+func gitToplevel(root string) (dir string, found bool, err error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("can't run git: %w", err)
+	}
+	return strings.TrimSpace(string(out)), true, nil
+}
+
+// moduleVersions returns the version of each module, read from vendor/modules.txt when present
+// and from the require directives of go.mod otherwise
+func moduleVersions(mods *wwhrd.VendorModules, reqs map[string]*wwhrd.Requirement) map[string]string {
+	versions := make(map[string]string)
+	if len(mods.Modules) > 0 {
+		for _, m := range mods.Modules {
+			versions[m.Path] = m.Version
+		}
+		return versions
+	}
+	for path, req := range reqs {
+		versions[path] = req.Version
+	}
+	return versions
+}
+
+// modulesAt returns the version of each module at ref in the local git repository
+func modulesAt(root, ref string) (map[string]string, error) {
+	if err := verifyRef(root, ref); err != nil {
+		return nil, err
+	}
+
+	mods := &wwhrd.VendorModules{Packages: make(map[string]*wwhrd.Module)}
+	content, found, err := gitShow(root, ref, "vendor/modules.txt")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if mods, err = wwhrd.ParseVendorModules(bytes.NewReader(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	reqs := make(map[string]*wwhrd.Requirement)
+	content, found, err = gitShow(root, ref, "go.mod")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if reqs, err = wwhrd.ParseGoModRequires(bytes.NewReader(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	return moduleVersions(mods, reqs), nil
+}
+
+// changedModules returns the modules that were added or changed version from old to current
+func changedModules(old, current map[string]string) []string {
+	var changed []string
+	for path, version := range current {
+		if v, ok := old[path]; !ok || v != version {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// inModules returns a filter selecting the packages provided by one of the modules, a package
+// belongs to the module with the longest matching path. Packages that don't belong to any of the
+// known modules are always selected, as nothing tells they didn't change.
+func inModules(all map[string]string, modules []string) func(pkg string) bool {
+	known := &wwhrd.VendorModules{Packages: make(map[string]*wwhrd.Module)}
+	for path := range all {
+		known.Modules = append(known.Modules, &wwhrd.Module{Path: path})
+	}
+	keep := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		keep[m] = true
+	}
+
+	return func(pkg string) bool {
+		m := known.Lookup(pkg)
+		return m == nil || keep[m.Path]
+	}
+}
+
+// changedSince returns a filter selecting the packages provided by modules added or changed since ref
+func changedSince(root, ref string) (func(pkg string) bool, error) {
+	old, err := modulesAt(root, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	mods, err := wwhrd.ReadVendorModules(root)
+	if err != nil {
+		return nil, err
+	}
+	reqs, err := wwhrd.ReadGoModRequires(root)
+	if err != nil {
+		return nil, err
+	}
+
+	current := moduleVersions(mods, reqs)
+	if len(current) == 0 {
+		return nil, fmt.Errorf("can't tell the modules changed since %s, neither vendor/modules.txt nor the requirements of go.mod list any", ref)
+	}
+	changed := changedModules(old, current)
+	for _, m := range changed {
+		log.WithFields(log.Fields{
+			"module": m,
+			"since":  ref,
+		}).Debug("Found changed module")
+	}
+
+	log.Infof("Checking packages from %d modules changed since %s", len(changed), ref)
+
+	return inModules(current, changed), nil
+}
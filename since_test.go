@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mockModulesTxt = `# github.com/fake/package v1.2.3
+## explicit; go 1.17
+github.com/fake/package
+# github.com/fake/nested v0.1.0 => github.com/fork/nested v0.1.1
+github.com/fake/nested/inside/a/package
+`
+
+func TestChangedModules(t *testing.T) {
+	old := map[string]string{
+		"github.com/a/b": "v1.0.0",
+		"github.com/c/d": "v1.0.0",
+		"github.com/e/f": "v1.0.0",
+	}
+	current := map[string]string{
+		"github.com/a/b": "v1.0.0",
+		"github.com/c/d": "v1.1.0",
+		"github.com/g/h": "v0.1.0",
+	}
+
+	assert.Equal(t, []string{"github.com/c/d", "github.com/g/h"}, changedModules(old, current))
+	assert.Nil(t, changedModules(current, current))
+}
+
+// filterPackages returns the packages of list selected by include
+func filterPackages(list []string, include func(pkg string) bool) []string {
+	var filtered []string
+	for _, pkg := range list {
+		if include(pkg) {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
+
+func TestInModules(t *testing.T) {
+	list := []string{"github.com/a/b", "github.com/a/b/c", "github.com/a/b/sub/d", "github.com/e/f"}
+	all := map[string]string{
+		"github.com/a/b":     "v1.0.0",
+		"github.com/a/b/sub": "v1.0.0",
+		"github.com/e/f":     "v1.0.0",
+	}
+
+	// packages of nested modules belong to the nested module
+	assert.Equal(t, []string{"github.com/a/b", "github.com/a/b/c"}, filterPackages(list, inModules(all, []string{"github.com/a/b"})))
+	assert.Equal(t, []string{"github.com/a/b/sub/d"}, filterPackages(list, inModules(all, []string{"github.com/a/b/sub"})))
+
+	// packages outside of the known modules are kept
+	assert.Equal(t, []string{"github.com/g/h"}, filterPackages([]string{"github.com/e/f", "github.com/g/h"}, inModules(all, nil)))
+}
+
+func TestChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir, err := ioutil.TempDir("", "TestChangedSince")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), []byte(mockModulesTxt), 0666))
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	updated := strings.Replace(mockModulesTxt, "v1.2.3", "v1.3.0", 1)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), []byte(updated), 0666))
+
+	list := []string{"github.com/fake/nested/inside/a/package", "github.com/fake/package"}
+	include, err := changedSince(dir, "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"github.com/fake/package"}, filterPackages(list, include))
+
+	_, err = changedSince(dir, "NONEXISTENT")
+	assert.EqualError(t, err, `can't find git ref "NONEXISTENT"`)
+
+	// files missing at ref make every module a changed one
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("readme"), 0666))
+	git("rm", "-q", "--cached", "vendor/modules.txt")
+	git("add", "README")
+	git("commit", "-q", "-m", "drop vendor")
+	include, err = changedSince(dir, "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, list, filterPackages(list, include))
+
+	// without module information nothing tells which packages changed
+	assert.NoError(t, os.Remove(filepath.Join(dir, "vendor", "modules.txt")))
+	_, err = changedSince(dir, "HEAD")
+	assert.EqualError(t, err, "can't tell the modules changed since HEAD, neither vendor/modules.txt nor the requirements of go.mod list any")
+}
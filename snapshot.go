@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	log "github.com/sirupsen/logrus"
+)
+
+// LicenseSnapshot is a license inventory, recorded to be compared with later runs
+type LicenseSnapshot struct {
+	// Baseline is set when only the failing packages are recorded, see NewBaseline
+	Baseline bool            `json:"baseline,omitempty"`
+	Packages []SnapshotEntry `json:"packages"`
+}
+
+// SnapshotEntry is the license of a package at the time of a snapshot
+type SnapshotEntry struct {
+	Package  string         `json:"package"`
+	Module   string         `json:"module,omitempty"`
+	Version  string         `json:"version,omitempty"`
+	License  string         `json:"license"`
+	Decision wwhrd.Decision `json:"decision,omitempty"`
+}
+
+// SnapshotDiff lists the differences between two snapshots
+type SnapshotDiff struct {
+	Added       []SnapshotEntry
+	Removed     []SnapshotEntry
+	Relicensed  []Relicense
+	NewLicenses []string
+}
+
+// Relicense is a package whose license changed between two snapshots
+type Relicense struct {
+	Package    string
+	OldLicense string
+	NewLicense string
+}
+
+// NewSnapshot records results, entries are sorted by package to keep the file diff-friendly
+func NewSnapshot(results []wwhrd.PackageResult) *LicenseSnapshot {
+	s := &LicenseSnapshot{Packages: []SnapshotEntry{}}
+	for _, r := range results {
+		s.Packages = append(s.Packages, SnapshotEntry{
+			Package:  r.Package,
+			Module:   r.Module,
+			Version:  r.Version,
+			License:  r.License,
+			Decision: r.Decision,
+		})
+	}
+	sort.Slice(s.Packages, func(i, j int) bool {
+		return s.Packages[i].Package < s.Packages[j].Package
+	})
+	return s
+}
+
+// NewBaseline records the denied results along with their license, leaving out their module
+// version so that the file only changes along with the violations
+func NewBaseline(results []wwhrd.PackageResult) *LicenseSnapshot {
+	s := &LicenseSnapshot{Baseline: true, Packages: []SnapshotEntry{}}
+	for _, r := range results {
+		if r.Decision != wwhrd.DecisionDenied && r.Decision != wwhrd.DecisionBaselined {
+			continue
+		}
+		s.Packages = append(s.Packages, SnapshotEntry{
+			Package:  r.Package,
+			License:  r.License,
+			Decision: wwhrd.DecisionDenied,
+		})
+	}
+	sort.Slice(s.Packages, func(i, j int) bool {
+		return s.Packages[i].Package < s.Packages[j].Package
+	})
+	return s
+}
+
+// ReadSnapshot reads a snapshot written by LicenseSnapshot.Write
+func ReadSnapshot(path string) (*LicenseSnapshot, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read snapshot file: %s", err)
+	}
+
+	s := &LicenseSnapshot{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("can't read snapshot file %s: %s", path, err)
+	}
+	return s, nil
+}
+
+// Filter returns the snapshot of the packages selected by include, or the snapshot itself when
+// include is nil
+func (s *LicenseSnapshot) Filter(include func(pkg string) bool) *LicenseSnapshot {
+	if include == nil {
+		return s
+	}
+	f := &LicenseSnapshot{Baseline: s.Baseline, Packages: []SnapshotEntry{}}
+	for _, e := range s.Packages {
+		if include(e.Package) {
+			f.Packages = append(f.Packages, e)
+		}
+	}
+	return f
+}
+
+// Write writes the snapshot as indented JSON
+func (s *LicenseSnapshot) Write(w io.Writer) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// ApplyBaseline marks as baselined the denied results that were already denied in the baseline
+// with the same license, returning how many were marked
+func ApplyBaseline(results []wwhrd.PackageResult, baseline *LicenseSnapshot) int {
+	known := make(map[string]string)
+	for _, e := range baseline.Packages {
+		if e.Decision == wwhrd.DecisionDenied || e.Decision == wwhrd.DecisionBaselined {
+			known[e.Package] = e.License
+		}
+	}
+
+	var n int
+	for i, r := range results {
+		if lic, ok := known[r.Package]; ok && r.Decision == wwhrd.DecisionDenied && lic == r.License {
+			results[i].Decision = wwhrd.DecisionBaselined
+			n++
+		}
+	}
+	return n
+}
+
+// DiffSnapshots returns the packages added, removed and relicensed from old to latest,
+// along with the licenses that were not used by any package in old
+func DiffSnapshots(old, latest *LicenseSnapshot) *SnapshotDiff {
+	d := &SnapshotDiff{}
+
+	oldPkgs := make(map[string]SnapshotEntry)
+	oldLicenses := make(map[string]bool)
+	for _, e := range old.Packages {
+		oldPkgs[e.Package] = e
+		oldLicenses[e.License] = true
+	}
+
+	newPkgs := make(map[string]bool)
+	newLicenses := make(map[string]bool)
+	for _, e := range latest.Packages {
+		newPkgs[e.Package] = true
+		if !oldLicenses[e.License] && !newLicenses[e.License] {
+			newLicenses[e.License] = true
+			d.NewLicenses = append(d.NewLicenses, e.License)
+		}
+
+		o, ok := oldPkgs[e.Package]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, e)
+		case o.License != e.License:
+			d.Relicensed = append(d.Relicensed, Relicense{Package: e.Package, OldLicense: o.License, NewLicense: e.License})
+		}
+	}
+
+	for _, e := range old.Packages {
+		if !newPkgs[e.Package] {
+			d.Removed = append(d.Removed, e)
+		}
+	}
+
+	sort.Strings(d.NewLicenses)
+	return d
+}
+
+// Empty reports whether the snapshots were identical, license-wise
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Relicensed) == 0
+}
+
+// logDiff logs the differences between two snapshots, license changes are logged as warnings
+func logDiff(d *SnapshotDiff) {
+	for _, l := range d.NewLicenses {
+		log.WithFields(log.Fields{
+			"license": l,
+		}).Warn("Found new license")
+	}
+
+	for _, r := range d.Relicensed {
+		log.WithFields(log.Fields{
+			"package":     r.Package,
+			"old_license": r.OldLicense,
+			"license":     r.NewLicense,
+		}).Warn("Found relicensed package")
+	}
+
+	for _, e := range d.Added {
+		log.WithFields(log.Fields{
+			"package": e.Package,
+			"license": e.License,
+		}).Info("Found added package")
+	}
+
+	for _, e := range d.Removed {
+		log.WithFields(log.Fields{
+			"package": e.Package,
+			"license": e.License,
+		}).Info("Found removed package")
+	}
+
+	if d.Empty() {
+		log.Info("No license changes found")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	s := NewSnapshot([]wwhrd.PackageResult{mockResults[3], mockResults[0]})
+
+	assert.Equal(t, []SnapshotEntry{
+		{Package: "github.com/a/b", Module: "github.com/a/b", Version: "v1.0.0", License: "MIT", Decision: wwhrd.DecisionApproved},
+		{Package: "github.com/f/g", Module: "github.com/f/g", License: "UNKNOWN", Decision: wwhrd.DecisionDenied},
+	}, s.Packages)
+}
+
+func TestNewBaseline(t *testing.T) {
+	results := append([]wwhrd.PackageResult{
+		{Package: "github.com/h/i", Module: "github.com/h/i", Version: "v2.0.0", License: "GPL-2.0", Decision: wwhrd.DecisionBaselined},
+	}, mockResults...)
+	s := NewBaseline(results)
+
+	// only the failing packages are recorded, without their version
+	assert.True(t, s.Baseline)
+	assert.Equal(t, []SnapshotEntry{
+		{Package: "github.com/f/g", License: "UNKNOWN", Decision: wwhrd.DecisionDenied},
+		{Package: "github.com/h/i", License: "GPL-2.0", Decision: wwhrd.DecisionDenied},
+	}, s.Packages)
+
+	assert.Equal(t, 1, ApplyBaseline([]wwhrd.PackageResult{mockResults[3]}, s))
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSnapshotRoundTrip")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewSnapshot(mockResults)
+	var out bytes.Buffer
+	assert.NoError(t, s.Write(&out))
+
+	path := filepath.Join(dir, "snapshot.json")
+	assert.NoError(t, ioutil.WriteFile(path, out.Bytes(), 0644))
+
+	read, err := ReadSnapshot(path)
+	assert.NoError(t, err)
+	assert.Equal(t, s, read)
+
+	_, err = ReadSnapshot(filepath.Join(dir, "NONEXISTENT"))
+	assert.Error(t, err)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	old := &LicenseSnapshot{Packages: []SnapshotEntry{
+		{Package: "github.com/a/b", License: "MIT"},
+		{Package: "github.com/d/e", License: "MIT"},
+		{Package: "github.com/f/g", License: "Apache-2.0"},
+	}}
+	latest := &LicenseSnapshot{Packages: []SnapshotEntry{
+		{Package: "github.com/a/b", License: "MIT"},
+		{Package: "github.com/d/e", License: "GPL-2.0"},
+		{Package: "github.com/h/i", License: "BSD-3-Clause"},
+	}}
+
+	d := DiffSnapshots(old, latest)
+	assert.Equal(t, []SnapshotEntry{{Package: "github.com/h/i", License: "BSD-3-Clause"}}, d.Added)
+	assert.Equal(t, []SnapshotEntry{{Package: "github.com/f/g", License: "Apache-2.0"}}, d.Removed)
+	assert.Equal(t, []Relicense{{Package: "github.com/d/e", OldLicense: "MIT", NewLicense: "GPL-2.0"}}, d.Relicensed)
+	assert.Equal(t, []string{"BSD-3-Clause", "GPL-2.0"}, d.NewLicenses)
+	assert.False(t, d.Empty())
+
+	assert.True(t, DiffSnapshots(old, old).Empty())
+}
+
+func TestApplyBaseline(t *testing.T) {
+	baseline := &LicenseSnapshot{Packages: []SnapshotEntry{
+		{Package: "github.com/a/b", License: "GPL-2.0", Decision: wwhrd.DecisionDenied},
+		{Package: "github.com/d/e", License: "MIT", Decision: wwhrd.DecisionDenied},
+		{Package: "github.com/f/g", License: "GPL-2.0", Decision: wwhrd.DecisionApproved},
+	}}
+	results := []wwhrd.PackageResult{
+		{Package: "github.com/a/b", License: "GPL-2.0", Decision: wwhrd.DecisionDenied},
+		// relicensed since the baseline was recorded
+		{Package: "github.com/d/e", License: "GPL-2.0", Decision: wwhrd.DecisionDenied},
+		{Package: "github.com/f/g", License: "GPL-2.0", Decision: wwhrd.DecisionDenied},
+		{Package: "github.com/h/i", License: "MIT", Decision: wwhrd.DecisionApproved},
+	}
+
+	assert.Equal(t, 1, ApplyBaseline(results, baseline))
+	assert.Equal(t, wwhrd.DecisionBaselined, results[0].Decision)
+	assert.Equal(t, wwhrd.DecisionDenied, results[1].Decision)
+	assert.Equal(t, wwhrd.DecisionDenied, results[2].Decision)
+	assert.Equal(t, wwhrd.DecisionApproved, results[3].Decision)
+}
+
+func TestSnapshotFilter(t *testing.T) {
+	s := NewSnapshot(mockResults)
+	assert.Same(t, s, s.Filter(nil))
+
+	f := s.Filter(func(pkg string) bool { return pkg == "github.com/f/g" })
+	assert.Equal(t, []SnapshotEntry{
+		{Package: "github.com/f/g", Module: "github.com/f/g", License: "UNKNOWN", Decision: wwhrd.DecisionDenied},
+	}, f.Packages)
+	assert.Len(t, s.Packages, len(mockResults))
+}
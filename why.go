@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+)
+
+// importChains returns the shortest chains of imports leading from one package to another, at most
+// max of them unless max is 0, truncated being set when some were left out. The number of
+// shortest chains grows exponentially with the diamonds of the graph.
+func importChains(imports []wwhrd.Import, from, to string, max int) (chains [][]wwhrd.Import, truncated bool) {
+	// keep a single, deterministic, import statement per pair of packages
+	sorted := append([]wwhrd.Import(nil), imports...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].File != sorted[j].File {
+			return sorted[i].File < sorted[j].File
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+	adjacency := make(map[string][]wwhrd.Import)
+	seen := make(map[[2]string]bool)
+	for _, e := range sorted {
+		if seen[[2]string{e.From, e.To}] {
+			continue
+		}
+		seen[[2]string{e.From, e.To}] = true
+		adjacency[e.From] = append(adjacency[e.From], e)
+	}
+	for k := range adjacency {
+		sort.Slice(adjacency[k], func(i, j int) bool {
+			return adjacency[k][i].To < adjacency[k][j].To
+		})
+	}
+
+	// BFS from the source, recording for each package the edges reaching it at the shortest distance
+	dist := map[string]int{from: 0}
+	parents := make(map[string][]wwhrd.Import)
+	queue := []string{from}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if pkg == to {
+			continue
+		}
+		for _, e := range adjacency[pkg] {
+			d, ok := dist[e.To]
+			if !ok {
+				dist[e.To] = dist[pkg] + 1
+				queue = append(queue, e.To)
+			} else if d != dist[pkg]+1 {
+				continue
+			}
+			parents[e.To] = append(parents[e.To], e)
+		}
+	}
+
+	if _, ok := dist[to]; !ok || from == to {
+		return nil, false
+	}
+
+	// walk back from the target to enumerate the shortest chains
+	var walk func(pkg string, suffix []wwhrd.Import)
+	walk = func(pkg string, suffix []wwhrd.Import) {
+		if truncated {
+			return
+		}
+		if pkg == from {
+			if max > 0 && len(chains) == max {
+				truncated = true
+				return
+			}
+			chains = append(chains, append([]wwhrd.Import(nil), suffix...))
+			return
+		}
+		for _, e := range parents[pkg] {
+			walk(e.From, append([]wwhrd.Import{e}, suffix...))
+		}
+	}
+	walk(to, nil)
+
+	return chains, truncated
+}
+
+// writeChains prints the import chains leading from one package to another, noting when some of
+// them were left out
+func writeChains(w io.Writer, chains [][]wwhrd.Import, truncated bool, from, to string) error {
+	if _, err := fmt.Fprintf(w, "# %s (from %s)\n", to, from); err != nil {
+		return err
+	}
+
+	if len(chains) == 0 {
+		_, err := fmt.Fprintf(w, "(%s does not import %s)\n\n", from, to)
+		return err
+	}
+
+	for _, chain := range chains {
+		for _, e := range chain {
+			if _, err := fmt.Fprintf(w, "%s\n\t%s:%d\n", e.From, e.File, e.Line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s\n\n", to); err != nil {
+			return err
+		}
+	}
+
+	if truncated {
+		if _, err := fmt.Fprintf(w, "(more chains not shown, see --max-chains)\n\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
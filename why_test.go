@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/frapposelli/wwhrd/pkg/wwhrd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportChains(t *testing.T) {
+	imports := []wwhrd.Import{
+		{From: "root", To: "a", File: "main.go", Line: 3},
+		{From: "root", To: "b", File: "main.go", Line: 4},
+		{From: "root", To: "a", File: "cmd/cmd.go", Line: 5},
+		{From: "a", To: "c", File: "vendor/a/a.go", Line: 3},
+		{From: "b", To: "c", File: "vendor/b/b.go", Line: 3},
+		{From: "c", To: "d", File: "vendor/c/c.go", Line: 3},
+		{From: "root", To: "d", File: "main.go", Line: 5},
+	}
+
+	chains, truncated := importChains(imports, "root", "c", 0)
+	assert.Equal(t, [][]wwhrd.Import{
+		{{From: "root", To: "a", File: "cmd/cmd.go", Line: 5}, {From: "a", To: "c", File: "vendor/a/a.go", Line: 3}},
+		{{From: "root", To: "b", File: "main.go", Line: 4}, {From: "b", To: "c", File: "vendor/b/b.go", Line: 3}},
+	}, chains)
+	assert.False(t, truncated)
+
+	// only the shortest chains are returned
+	chains, _ = importChains(imports, "root", "d", 0)
+	assert.Equal(t, [][]wwhrd.Import{{{From: "root", To: "d", File: "main.go", Line: 5}}}, chains)
+
+	chains, _ = importChains(imports, "b", "d", 0)
+	assert.Len(t, chains, 1)
+	chains, _ = importChains(imports, "c", "a", 0)
+	assert.Nil(t, chains)
+	chains, _ = importChains(imports, "root", "root", 0)
+	assert.Nil(t, chains)
+}
+
+func TestImportChainsMax(t *testing.T) {
+	// 20 diamonds in a row make 2^20 shortest chains
+	var imports []wwhrd.Import
+	from := "root"
+	for i := 0; i < 20; i++ {
+		to := fmt.Sprintf("p%d", i)
+		for _, via := range []string{to + "a", to + "b"} {
+			imports = append(imports, wwhrd.Import{From: from, To: via}, wwhrd.Import{From: via, To: to})
+		}
+		from = to
+	}
+
+	chains, truncated := importChains(imports, "root", "p19", 10)
+	assert.Len(t, chains, 10)
+	assert.True(t, truncated)
+	assert.Equal(t, "p0a", chains[0][0].To)
+	assert.Equal(t, "p19a", chains[0][39].From)
+	assert.Equal(t, "p19a", chains[1][39].From)
+	assert.Equal(t, "p0b", chains[1][0].To)
+
+	chains, truncated = importChains(imports, "root", "p1", 4)
+	assert.Len(t, chains, 4)
+	assert.False(t, truncated)
+}
+
+func TestWriteChains(t *testing.T) {
+	var out bytes.Buffer
+	chains := [][]wwhrd.Import{{{From: "root", To: "a", File: "main.go", Line: 3}}}
+
+	assert.NoError(t, writeChains(&out, chains, false, "root", "a"))
+	assert.Equal(t, "# a (from root)\nroot\n\tmain.go:3\na\n\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, writeChains(&out, chains, true, "root", "a"))
+	assert.Equal(t, "# a (from root)\nroot\n\tmain.go:3\na\n\n(more chains not shown, see --max-chains)\n\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, writeChains(&out, nil, false, "b", "a"))
+	assert.Equal(t, "# a (from b)\n(b does not import a)\n\n", out.String())
+}
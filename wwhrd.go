@@ -10,24 +10,38 @@ import (
 
 // Initialize and run wwhrd
 func main() {
-	parser := newCli()
-	c, err := parser.Parse()
-	if err != nil {
-		if _, ok := err.(*flags.Error); ok {
-			typ := err.(*flags.Error).Type
-			switch {
-			case typ == VersionHelp:
-				fmt.Println(err.(*flags.Error).Message)
-			case typ == flags.ErrHelp:
-				parser.WriteHelp(os.Stdout)
-			case typ == flags.ErrCommandRequired && len(c[0]) == 0:
-				parser.WriteHelp(os.Stdout)
-			default:
-				log.Info(err.Error() + fmt.Sprint(typ))
-				parser.WriteHelp(os.Stdout)
-			}
-		} else {
-			log.Fatalf("Exiting: %s", err.Error())
+	if code := run(newCli(), os.Args[1:]); code != exitOK {
+		os.Exit(code)
+	}
+}
+
+// run parses args and runs the command, returning the exit code
+func run(parser *flags.Parser, args []string) int {
+	c, err := parser.ParseArgs(args)
+	if err == nil {
+		return exitOK
+	}
+
+	if _, ok := err.(*flags.Error); ok {
+		typ := err.(*flags.Error).Type
+		switch {
+		case typ == VersionHelp:
+			fmt.Println(err.(*flags.Error).Message)
+			return exitOK
+		case typ == flags.ErrHelp:
+			parser.WriteHelp(os.Stdout)
+			return exitOK
+		case typ == flags.ErrCommandRequired && len(c) > 0 && len(c[0]) == 0:
+			parser.WriteHelp(os.Stdout)
+			return exitOK
+		default:
+			// usage errors, e.g. an invalid option value, must not let CI go green
+			log.Errorf("%s (%s)", err.Error(), typ)
+			parser.WriteHelp(os.Stderr)
+			return exitError
 		}
 	}
+
+	log.StandardLogger().Logf(log.FatalLevel, "Exiting: %s", err.Error())
+	return exitCode(err)
 }
@@ -11,6 +11,19 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// TestMain keeps the license cache of the commands under test out of the user cache directory
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "wwhrd-cache")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheDir = dir
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func mockGoPackageDir(t *testing.T, prefix string) (dir string, rm func()) {
 
 	dir, err := ioutil.TempDir("", prefix)
@@ -42,6 +55,7 @@ func mockGoPackageDir(t *testing.T, prefix string) (dir string, rm func()) {
 		{".wwhrd-botched.yml", []byte(mockConfBotched)},
 		{filepath.Join("vendor/github.com/fake/package", "mockpkg.go"), []byte(mockVendor)},
 		{filepath.Join("vendor/github.com/fake/package", "LICENSE"), []byte(mockLicense)}, // American English spelling
+		{filepath.Join("vendor/github.com/fake/package", "NOTICE"), []byte(mockNotice)},
 		{filepath.Join("vendor/github.com/faux/package", "mockpkg.go"), []byte(mockVendor)},
 		{filepath.Join("vendor/github.com/faux/package", "LICENCE"), []byte(mockLicense)}, // British English spelling
 		{filepath.Join("vendor/github.com/fake/nested", "LICENSE"), []byte(mockLicense)},
@@ -85,6 +99,24 @@ func TestCliCommandsErrors(t *testing.T) {
 	}
 }
 
+func TestRunUsageErrors(t *testing.T) {
+	cases := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"--version"}, exitOK},
+		{[]string{"--help"}, exitOK},
+		{[]string{"--jobs", "0", "list"}, exitError},
+		{[]string{"--timeout", "bogus", "list"}, exitError},
+		{[]string{"check", "--format", "bogus"}, exitError},
+		{[]string{"bogus"}, exitError},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, run(newCli(), c.args), c.args)
+	}
+}
+
 var mockConf = `---
 allowlist:
   - BSD-3-Clause
@@ -123,6 +155,10 @@ var mockVendor = `package main
 func main() {}
 `
 
+var mockNotice = `Fake Package
+Copyright 2016 The Fake Authors
+`
+
 var mockLicense = `Copyright (c) 2016, Fabio Rapposelli
 All rights reserved.
 